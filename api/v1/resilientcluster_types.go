@@ -36,11 +36,121 @@ type (
 		Time         metav1.Time         `json:"time,omitempty"`
 	}
 
+	// MetadataType is the type of metadata changed, use MetadataLabel and MetadataAnnotation.
+	MetadataType string
+
+	// MetadataChange represents a label or an annotation set on the Spoke's ManagedCluster when replacing a previous
+	// Spoke cluster.
+	MetadataChange struct {
+		// +kubebuilder:validation:Enum=Label;Annotation
+		Type          MetadataType `json:"type"`
+		Key           string       `json:"key"`
+		Value         string       `json:"value"`
+		PreviousValue string       `json:"previousValue,omitempty"`
+	}
+
+	// ActionOutcome represents the outcome of an action performed when replacing a previous Spoke cluster.
+	ActionOutcome struct {
+		Action    string `json:"action"`
+		Succeeded bool   `json:"succeeded"`
+		Message   string `json:"message,omitempty"`
+	}
+
+	// HookPhase is the phase of a replacement a hook is invoked in, use HookPreFailover and HookPostFailover.
+	HookPhase string
+
+	// HookResult represents the outcome of a hook invoked before or after replacing a Spoke cluster.
+	HookResult struct {
+		Name string `json:"name"`
+		// +kubebuilder:validation:Enum=PreFailover;PostFailover
+		Phase     HookPhase `json:"phase"`
+		Succeeded bool      `json:"succeeded"`
+		// Vetoed is true if the failed hook vetoed the failover.
+		Vetoed     bool        `json:"vetoed,omitempty"`
+		Message    string      `json:"message,omitempty"`
+		StartTime  metav1.Time `json:"startTime,omitempty"`
+		FinishTime metav1.Time `json:"finishTime,omitempty"`
+	}
+
+	// RestoreStatus represents the restore of the previous Spoke cluster's workload from its latest Velero Backup.
+	RestoreStatus struct {
+		BackupName string `json:"backupName"`
+		Phase      string `json:"phase,omitempty"`
+	}
+
+	// ReplacementPhase is the phase of a replacement, use ReplacementValidating, ReplacementReplaced, and
+	// ReplacementDegraded.
+	ReplacementPhase string
+
+	// ValidationCheckType is the type of resource checked when validating a replacement, use ValidationAddon,
+	// ValidationManifestWork, ValidationPolicy, and ValidationSmokeCheck.
+	ValidationCheckType string
+
+	// ValidationCheck represents the outcome of checking a resource in the replacement Spoke cluster's namespace.
+	ValidationCheck struct {
+		// +kubebuilder:validation:Enum=ManagedClusterAddOn;ManifestWork;Policy;SmokeCheck
+		Type    ValidationCheckType `json:"type"`
+		Name    string              `json:"name"`
+		Passed  bool                `json:"passed"`
+		Message string              `json:"message,omitempty"`
+	}
+
+	// ReplacementStatus records the replacement of a previous Spoke cluster by the one represented by the
+	// ResilientCluster.
+	ReplacementStatus struct {
+		PreviousSpoke string          `json:"previousSpoke"`
+		Reason        string          `json:"reason,omitempty"`
+		ClaimName     string          `json:"claimName,omitempty"`
+		PoolName      string          `json:"poolName,omitempty"`
+		StartTime     metav1.Time     `json:"startTime,omitempty"`
+		FinishTime    metav1.Time     `json:"finishTime,omitempty"`
+		Actions       []ActionOutcome `json:"actions,omitempty"`
+		CopiedSecrets []string        `json:"copiedSecrets,omitempty"`
+		// ManifestWorks lists the ManifestWorks copied to the replacement Spoke cluster, their applied status is
+		// reported in Validation.
+		ManifestWorks []string `json:"manifestWorks,omitempty"`
+		// Placements lists the Placements with decisions for the previous Spoke cluster, rescheduled once it is deleted.
+		Placements []string `json:"placements,omitempty"`
+		// Policies lists the governance policies and policy sets whose placements were rewritten to target the
+		// replacement Spoke cluster.
+		Policies              []string         `json:"policies,omitempty"`
+		ManagedClusterChanges []MetadataChange `json:"managedClusterChanges,omitempty"`
+		BackupLocation        string           `json:"backupLocation,omitempty"`
+		RetainedUntil         *metav1.Time     `json:"retainedUntil,omitempty"`
+		Restore               *RestoreStatus   `json:"restore,omitempty"`
+		// ReplicatedVolumes lists the previous Spoke's replicated PersistentVolumeClaims, recovered on the replacement
+		// Spoke cluster by their replication tooling.
+		ReplicatedVolumes []string `json:"replicatedVolumes,omitempty"`
+		// UnreplicatedVolumes lists the previous Spoke's PersistentVolumeClaims with no replication, their data is lost.
+		UnreplicatedVolumes []string     `json:"unreplicatedVolumes,omitempty"`
+		Hooks               []HookResult `json:"hooks,omitempty"`
+		// Phase is Validating until the replacement Spoke cluster is validated, and Replaced or Degraded after.
+		// +kubebuilder:validation:Enum=Validating;Replaced;Degraded
+		Phase ReplacementPhase `json:"phase,omitempty"`
+		// Validation lists the latest checks performed for validating the replacement Spoke cluster.
+		Validation    []ValidationCheck `json:"validation,omitempty"`
+		ValidatedTime *metav1.Time      `json:"validatedTime,omitempty"`
+	}
+
 	// ResilientClusterStatus encapsulated the initial, current, and previous statuses of the ResilientCluster.
 	ResilientClusterStatus struct {
-		InitialStatus  ClusterStatus `json:"initialStatus"`
-		CurrentStatus  ClusterStatus `json:"currentStatus"`
-		PreviousStatus ClusterStatus `json:"previousStatus,omitempty"`
+		InitialStatus  ClusterStatus      `json:"initialStatus"`
+		CurrentStatus  ClusterStatus      `json:"currentStatus"`
+		PreviousStatus ClusterStatus      `json:"previousStatus,omitempty"`
+		Replacement    *ReplacementStatus `json:"replacement,omitempty"`
+		// LogicalName is the stable name of the cluster, preserved across replacements.
+		LogicalName string `json:"logicalName,omitempty"`
+		// Lineage lists the previous Spoke clusters that backed the logical cluster, oldest first.
+		Lineage []string `json:"lineage,omitempty"`
+		// History lists the latest replacements of the logical cluster, oldest first. The list is bounded, use Lineage
+		// for counting the replacements.
+		History []ReplacementStatus `json:"history,omitempty"`
+		// PreFailoverHooks lists the results of the hooks invoked before the latest attempt to replace the cluster.
+		PreFailoverHooks []HookResult `json:"preFailoverHooks,omitempty"`
+		// Suspended is true while the ResilientCluster is annotated as suspended, freezing the Addon's automation.
+		Suspended bool `json:"suspended,omitempty"`
+		// SuspendedTime is the time the ResilientCluster was suspended.
+		SuspendedTime *metav1.Time `json:"suspendedTime,omitempty"`
 	}
 
 	// ResilientCluster is used by the MultiCluster-Resiliency-Addon for maintain the status and state of each cluster
@@ -50,6 +160,9 @@ type (
 	// +kubebuilder:object:root=true
 	// +kubebuilder:resource:scope=Namespaced,shortName=rstc
 	// +kubebuilder:printcolumn:name=Available,type=string,JSONPath=`.status.currentStatus.availability`
+	// +kubebuilder:printcolumn:name=Logical-Name,type=string,JSONPath=`.status.logicalName`
+	// +kubebuilder:printcolumn:name=Replacement,type=string,JSONPath=`.status.replacement.phase`
+	// +kubebuilder:printcolumn:name=Suspended,type=boolean,JSONPath=`.status.suspended`
 	ResilientCluster struct {
 		metav1.TypeMeta   `json:",inline"`
 		metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -69,6 +182,21 @@ type (
 const (
 	ClusterAvailable    ClusterAvailability = "True"
 	ClusterNotAvailable ClusterAvailability = "False"
+
+	MetadataLabel      MetadataType = "Label"
+	MetadataAnnotation MetadataType = "Annotation"
+
+	HookPreFailover  HookPhase = "PreFailover"
+	HookPostFailover HookPhase = "PostFailover"
+
+	ReplacementValidating ReplacementPhase = "Validating"
+	ReplacementReplaced   ReplacementPhase = "Replaced"
+	ReplacementDegraded   ReplacementPhase = "Degraded"
+
+	ValidationAddon        ValidationCheckType = "ManagedClusterAddOn"
+	ValidationManifestWork ValidationCheckType = "ManifestWork"
+	ValidationPolicy       ValidationCheckType = "Policy"
+	ValidationSmokeCheck   ValidationCheckType = "SmokeCheck"
 )
 
 // init is used for registering the Addon API types with the scheme previously configured with groupVersion.
@@ -8,6 +8,7 @@ import (
 	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/agent"
 	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/version"
 	"open-cluster-management.io/addon-framework/pkg/cmd/factory"
+	"time"
 )
 
 // init is used for creating the Agent Commend, incorporate its flags, and binding it to the root MCRA Command.
@@ -23,6 +24,12 @@ func init() {
 	agtCmd.Flags().StringVar(&agt.Options.HubKubeConfigFile, "hub-kubeconfig", "blabla", "TODO")
 	agtCmd.Flags().StringVar(&agt.Options.SpokeName, "spoke-name", "blabla", "TODO")
 	agtCmd.Flags().StringVar(&agt.Options.AgentNamespace, "agent-namespace", "blabla", "TODO")
+	agtCmd.Flags().DurationVar(&agt.Options.InventoryInterval, "inventory-interval", 5*time.Minute, "Interval for reporting the Spoke workload inventory to the Hub, 0 disables reporting")
+
+	agtCmd.Flags().DurationVar(&agt.Options.BackupInterval, "backup-interval", 0, "Interval for creating Velero Backups of the Spoke workload namespaces, 0 disables backups")
+	agtCmd.Flags().StringVar(&agt.Options.VeleroNamespace, "velero-namespace", "openshift-adp", "Namespace Velero is installed in on the Spoke")
+	agtCmd.Flags().StringVar(&agt.Options.StorageLocation, "backup-storage-location", "", "Velero BackupStorageLocation for the Backups, defaults to Velero's default location")
+	agtCmd.Flags().DurationVar(&agt.Options.BackupTTL, "backup-ttl", 720*time.Hour, "Time to live for the Velero Backups")
 
 	mcraCmd.AddCommand(agtCmd)
 }
@@ -29,6 +29,9 @@ func init() {
 	mgrCmd.Flags().BoolVar(&mgr.Options.EnableValidation, "enable-validation-webhook", false, "TODO")
 	mgrCmd.Flags().BoolVar(&mgr.Options.InstallAllStrategy, "install-all-strategy", false, "TODO")
 	mgrCmd.Flags().StringVar(&mgr.Options.InstallAllNamespace, "install-all-namespace", "open-cluster-management-agent-addon", "TODO - depends on install-all-strategy")
+	mgrCmd.Flags().StringVar(&mgr.Options.EventSink, "event-sink", "", "URL for publishing the lifecycle CloudEvents, empty disables publishing")
+	mgrCmd.Flags().StringVar(&mgr.Options.EventSinkType, "event-sink-type", "http", "Type of the event sink, http or kafka for a Kafka REST proxy")
+	mgrCmd.Flags().StringVar(&mgr.Options.EventSinkTopic, "event-sink-topic", "", "Kafka topic for the kafka event sink")
 
 	mcraCmd.AddCommand(mgrCmd)
 }
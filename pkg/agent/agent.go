@@ -7,10 +7,13 @@ package agent
 import (
 	"context"
 	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/mcra"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"open-cluster-management.io/addon-framework/pkg/lease"
+	"time"
 )
 
 // Agent is a receiver representing the Addon agent. It encapsulates the Agent Options used for configuring the agent run.
@@ -24,6 +27,11 @@ type Options struct {
 	HubKubeConfigFile string
 	SpokeName         string
 	AgentNamespace    string
+	InventoryInterval time.Duration
+	BackupInterval    time.Duration
+	VeleroNamespace   string
+	StorageLocation   string
+	BackupTTL         time.Duration
 }
 
 // NewAgent is used as a factory for creating an Agent instance with an Options instance.
@@ -32,7 +40,9 @@ func NewAgent() Agent {
 }
 
 // Run is used for running the Addon agent. It takes a context and the kubeconfig for the Spoke it runs on. This
-// function blocks while waiting for the context to be done.
+// function blocks while waiting for the context to be done. When running multiple replicas, the agent is deployed with
+// leader election enabled, in which case Run is only invoked for the elected leader, making it the only replica
+// updating the lease against the Hub.
 func (a *Agent) Run(ctx context.Context, kubeConfig *rest.Config) error {
 	spokeClientSet, err := kubernetes.NewForConfig(kubeConfig)
 	if err != nil {
@@ -52,8 +62,82 @@ func (a *Agent) Run(ctx context.Context, kubeConfig *rest.Config) error {
 		leaseUpdater.Start(ctx)
 	}()
 
+	// periodically report the Spoke's workload inventory to the Spoke's cluster-namespace on the Hub
+	if a.Options.InventoryInterval > 0 {
+		reporter, err := a.newInventoryReporter(kubeConfig, hubConfig)
+		if err != nil {
+			return err
+		}
+
+		go wait.UntilWithContext(ctx, reporter.report, a.Options.InventoryInterval)
+	}
+
+	// periodically maintain a Velero Schedule for the Spoke's workload namespaces, and report the latest backup to the Hub
+	if a.Options.BackupInterval > 0 {
+		scheduler, err := a.newBackupScheduler(kubeConfig, hubConfig)
+		if err != nil {
+			return err
+		}
+
+		go wait.UntilWithContext(ctx, scheduler.schedule, a.Options.BackupInterval)
+	}
+
 	// blocking
 	<-ctx.Done()
 
 	return nil
 }
+
+// newInventoryReporter is used for creating an inventoryReporter with clients for both the Spoke and the Hub.
+func (a *Agent) newInventoryReporter(kubeConfig, hubConfig *rest.Config) (*inventoryReporter, error) {
+	spokeClientSet, err := kubernetes.NewForConfig(kubeConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	spokeDynamicClient, err := dynamic.NewForConfig(kubeConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	hubClientSet, err := kubernetes.NewForConfig(hubConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &inventoryReporter{
+		spokeClient:  spokeClientSet,
+		spokeDynamic: spokeDynamicClient,
+		hubClient:    hubClientSet,
+		spokeName:    a.Options.SpokeName,
+	}, nil
+}
+
+// newBackupScheduler is used for creating a backupScheduler with clients for both the Spoke and the Hub.
+func (a *Agent) newBackupScheduler(kubeConfig, hubConfig *rest.Config) (*backupScheduler, error) {
+	spokeClientSet, err := kubernetes.NewForConfig(kubeConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	spokeDynamicClient, err := dynamic.NewForConfig(kubeConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	hubClientSet, err := kubernetes.NewForConfig(hubConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &backupScheduler{
+		spokeClient:     spokeClientSet,
+		spokeDynamic:    spokeDynamicClient,
+		hubClient:       hubClientSet,
+		spokeName:       a.Options.SpokeName,
+		veleroNamespace: a.Options.VeleroNamespace,
+		storageLocation: a.Options.StorageLocation,
+		interval:        a.Options.BackupInterval,
+		ttl:             a.Options.BackupTTL,
+	}, nil
+}
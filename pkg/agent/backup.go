@@ -0,0 +1,195 @@
+// Copyright (c) 2023 Red Hat, Inc.
+
+package agent
+
+// This file hosts functions for maintaining a Velero backup Schedule on the Spoke and reporting the latest backup to the Hub.
+
+import (
+	"context"
+	"fmt"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/mcra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sort"
+	"time"
+)
+
+var (
+	// veleroSchedulesGVR is used for managing the Velero Schedule, installed on the Spoke by OADP or Velero.
+	veleroSchedulesGVR = schema.GroupVersionResource{Group: "velero.io", Version: "v1", Resource: "schedules"}
+	// veleroBackupsGVR is used for listing the Velero Backups created by the Schedule.
+	veleroBackupsGVR = schema.GroupVersionResource{Group: "velero.io", Version: "v1", Resource: "backups"}
+)
+
+// labelVeleroScheduleName is set by Velero on the Backups created by a Schedule.
+const labelVeleroScheduleName = "velero.io/schedule-name"
+
+// backupScheduler is used for maintaining a Velero Schedule backing up the Spoke's workload namespaces, and reporting
+// the Schedule's last backup and latest completed Backup to the Spoke's cluster-namespace on the Hub.
+type backupScheduler struct {
+	spokeClient     kubernetes.Interface
+	spokeDynamic    dynamic.Interface
+	hubClient       kubernetes.Interface
+	spokeName       string
+	veleroNamespace string
+	storageLocation string
+	interval        time.Duration
+	ttl             time.Duration
+}
+
+// schedule is used for creating or updating the Schedule with the current workload namespaces, and reporting its last
+// backup. A Backup is reported only when completed, so nothing is reported before Velero completes the first one.
+func (s *backupScheduler) schedule(ctx context.Context) {
+	logger := log.FromContext(ctx)
+
+	schedule, err := s.applySchedule(ctx)
+	if err != nil {
+		logger.Error(err, "failed applying backup schedule", "spoke", s.spokeName)
+		return
+	}
+	if schedule == nil {
+		return
+	}
+
+	latest, err := s.latestCompletedBackup(ctx, schedule.GetName())
+	if err != nil {
+		logger.Error(err, "failed listing backups", "spoke", s.spokeName)
+		return
+	}
+	if latest == nil {
+		logger.Info("no completed backups to report", "spoke", s.spokeName)
+		return
+	}
+
+	lastBackup, _, _ := unstructured.NestedString(schedule.Object, "status", "lastBackup")
+	completionTime, _, _ := unstructured.NestedString(latest.Object, "status", "completionTimestamp")
+	s.report(ctx, map[string]string{
+		"schedule_name":    schedule.GetName(),
+		"last_backup":      lastBackup,
+		"backup_name":      latest.GetName(),
+		"velero_namespace": s.veleroNamespace,
+		"completion_time":  completionTime,
+	})
+}
+
+// applySchedule is used for creating or updating a Velero Schedule including all the workload namespaces, named after
+// the Spoke, as Backups from all Spokes might share a storage location. Velero creates the Backups every interval, and
+// prunes the expired ones based on the ttl. Returns the Schedule, or nil if there are no workload namespaces.
+func (s *backupScheduler) applySchedule(ctx context.Context) (*unstructured.Unstructured, error) {
+	namespaceList, err := s.spokeClient.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var namespaces []interface{}
+	for _, ns := range namespaceList.Items {
+		if !isSystemNamespace(ns.Name) {
+			namespaces = append(namespaces, ns.Name)
+		}
+	}
+	// an empty namespaces list includes all of them in a Velero Backup
+	if len(namespaces) == 0 {
+		log.FromContext(ctx).Info("no workload namespaces to backup", "spoke", s.spokeName)
+		return nil, nil
+	}
+
+	template := map[string]interface{}{
+		"includedNamespaces": namespaces,
+		"ttl":                s.ttl.String(),
+	}
+	// the default storage location is used if not set
+	if s.storageLocation != "" {
+		template["storageLocation"] = s.storageLocation
+	}
+	spec := map[string]interface{}{
+		"schedule": fmt.Sprintf("@every %s", s.interval),
+		"template": template,
+	}
+
+	schedules := s.spokeDynamic.Resource(veleroSchedulesGVR).Namespace(s.veleroNamespace)
+	name := fmt.Sprintf("%s-mcra", s.spokeName)
+
+	current, err := schedules.Get(ctx, name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		schedule := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "velero.io/v1",
+			"kind":       "Schedule",
+			"spec":       spec,
+		}}
+		schedule.SetName(name)
+		schedule.SetNamespace(s.veleroNamespace)
+		schedule.SetLabels(map[string]string{mcra.LabelSpoke: s.spokeName})
+		return schedules.Create(ctx, schedule, metav1.CreateOptions{})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// the spec is only updated when changed, i.e. when workload namespaces are added or removed
+	if equality.Semantic.DeepEqual(current.Object["spec"], spec) {
+		return current, nil
+	}
+	current.Object["spec"] = spec
+	return schedules.Update(ctx, current, metav1.UpdateOptions{})
+}
+
+// latestCompletedBackup is used for fetching the latest completed Backup created by the Schedule. Returns nil if none.
+func (s *backupScheduler) latestCompletedBackup(ctx context.Context, scheduleName string) (*unstructured.Unstructured, error) {
+	backups, err := s.spokeDynamic.Resource(veleroBackupsGVR).Namespace(s.veleroNamespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", labelVeleroScheduleName, scheduleName),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var completed []unstructured.Unstructured
+	for _, backup := range backups.Items {
+		if phase, _, _ := unstructured.NestedString(backup.Object, "status", "phase"); phase == "Completed" {
+			completed = append(completed, backup)
+		}
+	}
+	if len(completed) == 0 {
+		return nil, nil
+	}
+
+	// RFC3339 timestamps sort lexically
+	sort.Slice(completed, func(i, j int) bool {
+		iTime, _, _ := unstructured.NestedString(completed[i].Object, "status", "completionTimestamp")
+		jTime, _, _ := unstructured.NestedString(completed[j].Object, "status", "completionTimestamp")
+		return iTime < jTime
+	})
+	return &completed[len(completed)-1], nil
+}
+
+// report is used for storing the latest Backup details in a ConfigMap on the Hub.
+func (s *backupScheduler) report(ctx context.Context, data map[string]string) {
+	logger := log.FromContext(ctx)
+
+	configMaps := s.hubClient.CoreV1().ConfigMaps(s.spokeName)
+	current, err := configMaps.Get(ctx, mcra.BackupConfigMapName, metav1.GetOptions{})
+	switch {
+	case errors.IsNotFound(err):
+		configMap := &corev1.ConfigMap{}
+		configMap.SetName(mcra.BackupConfigMapName)
+		configMap.SetNamespace(s.spokeName)
+		configMap.SetAnnotations(map[string]string{mcra.AnnotationCreatedBy: mcra.AddonName})
+		configMap.Data = data
+		if _, err = configMaps.Create(ctx, configMap, metav1.CreateOptions{}); err != nil {
+			logger.Error(err, "failed creating backup report", "spoke", s.spokeName)
+		}
+	case err != nil:
+		logger.Error(err, "failed fetching backup report", "spoke", s.spokeName)
+	default:
+		current.Data = data
+		if _, err = configMaps.Update(ctx, current, metav1.UpdateOptions{}); err != nil {
+			logger.Error(err, "failed updating backup report", "spoke", s.spokeName)
+		}
+	}
+}
@@ -0,0 +1,259 @@
+// Copyright (c) 2023 Red Hat, Inc.
+
+package agent
+
+// This file hosts functions for collecting the workload inventory on the Spoke and storing it on the Hub.
+
+import (
+	"context"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/inventory"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/mcra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sort"
+	"strings"
+)
+
+// systemNamespaces and systemNamespacePrefixes are used for excluding platform namespaces from the inventory, either by
+// their exact name or by their prefix.
+var (
+	systemNamespaces        = []string{"default", "openshift", "hive", "open-cluster-management"}
+	systemNamespacePrefixes = []string{"openshift-", "kube-", "hive-", "open-cluster-management-"}
+)
+
+// routesGVR is used for listing OpenShift Routes, Routes are only collected if the API is available on the Spoke.
+var routesGVR = schema.GroupVersionResource{Group: "route.openshift.io", Version: "v1", Resource: "routes"}
+
+// replicationSourcesGVR and volumeReplicationsGVR are used for listing the replications of PersistentVolumeClaims,
+// replications are only collected if the APIs are available on the Spoke.
+var (
+	replicationSourcesGVR = schema.GroupVersionResource{Group: "volsync.backube", Version: "v1alpha1", Resource: "replicationsources"}
+	volumeReplicationsGVR = schema.GroupVersionResource{Group: "replication.storage.openshift.io", Version: "v1alpha1", Resource: "volumereplications"}
+	volSyncMovers         = []string{"restic", "rsync", "rsyncTLS", "rclone", "syncthing", "external"}
+)
+
+// inventoryReporter is used for collecting the Spoke's workload inventory and storing it in the Spoke's
+// cluster-namespace on the Hub.
+type inventoryReporter struct {
+	spokeClient  kubernetes.Interface
+	spokeDynamic dynamic.Interface
+	hubClient    kubernetes.Interface
+	spokeName    string
+}
+
+// report is used for collecting a snapshot of the inventory and storing it in a ConfigMap on the Hub.
+func (r *inventoryReporter) report(ctx context.Context) {
+	logger := log.FromContext(ctx)
+
+	inv, err := r.collect(ctx)
+	if err != nil {
+		logger.Error(err, "failed collecting inventory", "spoke", r.spokeName)
+		return
+	}
+
+	data, err := inv.ToConfigMapData()
+	if err != nil {
+		logger.Error(err, "failed serializing inventory", "spoke", r.spokeName)
+		return
+	}
+
+	configMaps := r.hubClient.CoreV1().ConfigMaps(r.spokeName)
+	current, err := configMaps.Get(ctx, mcra.InventoryConfigMapName, metav1.GetOptions{})
+	switch {
+	case errors.IsNotFound(err):
+		configMap := &corev1.ConfigMap{}
+		configMap.SetName(mcra.InventoryConfigMapName)
+		configMap.SetNamespace(r.spokeName)
+		configMap.SetAnnotations(map[string]string{mcra.AnnotationCreatedBy: mcra.AddonName})
+		configMap.Data = data
+		if _, err = configMaps.Create(ctx, configMap, metav1.CreateOptions{}); err != nil {
+			logger.Error(err, "failed creating inventory", "spoke", r.spokeName)
+		}
+	case err != nil:
+		logger.Error(err, "failed fetching inventory", "spoke", r.spokeName)
+	default:
+		current.Data = data
+		if _, err = configMaps.Update(ctx, current, metav1.UpdateOptions{}); err != nil {
+			logger.Error(err, "failed updating inventory", "spoke", r.spokeName)
+		}
+	}
+}
+
+// collect is used for listing the workloads on the Spoke and building an Inventory, grouped by namespace.
+func (r *inventoryReporter) collect(ctx context.Context) (*inventory.Inventory, error) {
+	namespaces := map[string]*inventory.Namespace{}
+
+	namespaceList, err := r.spokeClient.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, ns := range namespaceList.Items {
+		if !isSystemNamespace(ns.Name) {
+			namespaces[ns.Name] = &inventory.Namespace{Name: ns.Name}
+		}
+	}
+
+	deployments, err := r.spokeClient.AppsV1().Deployments(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, deployment := range deployments.Items {
+		if ns, found := namespaces[deployment.Namespace]; found {
+			ns.Deployments = append(ns.Deployments, inventory.Workload{
+				Name:     deployment.Name,
+				Replicas: replicasOrDefault(deployment.Spec.Replicas),
+				Images:   containerImages(deployment.Spec.Template.Spec),
+			})
+		}
+	}
+
+	statefulSets, err := r.spokeClient.AppsV1().StatefulSets(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, statefulSet := range statefulSets.Items {
+		if ns, found := namespaces[statefulSet.Namespace]; found {
+			ns.StatefulSets = append(ns.StatefulSets, inventory.Workload{
+				Name:     statefulSet.Name,
+				Replicas: replicasOrDefault(statefulSet.Spec.Replicas),
+				Images:   containerImages(statefulSet.Spec.Template.Spec),
+			})
+		}
+	}
+
+	replications, err := r.collectReplications(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := r.spokeClient.CoreV1().PersistentVolumeClaims(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, claim := range claims.Items {
+		if ns, found := namespaces[claim.Namespace]; found {
+			newClaim := inventory.Claim{Name: claim.Name, Replication: replications[claim.Namespace+"/"+claim.Name]}
+			for _, mode := range claim.Spec.AccessModes {
+				newClaim.AccessModes = append(newClaim.AccessModes, string(mode))
+			}
+			if claim.Spec.StorageClassName != nil {
+				newClaim.StorageClass = *claim.Spec.StorageClassName
+			}
+			if capacity, found := claim.Status.Capacity[corev1.ResourceStorage]; found {
+				newClaim.Capacity = capacity.String()
+			}
+			ns.PersistentVolumeClaims = append(ns.PersistentVolumeClaims, newClaim)
+		}
+	}
+
+	// routes are not available on non-OpenShift Spokes
+	routes, err := r.spokeDynamic.Resource(routesGVR).Namespace(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, err
+	}
+	if err == nil {
+		for _, route := range routes.Items {
+			if ns, found := namespaces[route.GetNamespace()]; found {
+				host, _, _ := unstructured.NestedString(route.Object, "spec", "host")
+				ns.Routes = append(ns.Routes, inventory.Route{Name: route.GetName(), Host: host})
+			}
+		}
+	}
+
+	inv := &inventory.Inventory{SpokeName: r.spokeName, Time: metav1.Now()}
+	for _, ns := range namespaces {
+		inv.Namespaces = append(inv.Namespaces, *ns)
+	}
+	sort.Slice(inv.Namespaces, func(i, j int) bool {
+		return inv.Namespaces[i].Name < inv.Namespaces[j].Name
+	})
+
+	return inv, nil
+}
+
+// collectReplications is used for listing the VolSync ReplicationSources and the VolumeReplications on the Spoke.
+// Returns the replications keyed by their PersistentVolumeClaim's 'namespace/name'.
+func (r *inventoryReporter) collectReplications(ctx context.Context) (map[string]*inventory.Replication, error) {
+	replications := map[string]*inventory.Replication{}
+
+	// VolSync is not necessarily installed on the Spoke
+	sources, err := r.spokeDynamic.Resource(replicationSourcesGVR).Namespace(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, err
+	}
+	if err == nil {
+		for _, source := range sources.Items {
+			claimName, _, _ := unstructured.NestedString(source.Object, "spec", "sourcePVC")
+			replication := &inventory.Replication{Type: inventory.ReplicationVolSync, Name: source.GetName()}
+			for _, mover := range volSyncMovers {
+				if _, found, _ := unstructured.NestedMap(source.Object, "spec", mover); found {
+					replication.Mover = mover
+					break
+				}
+			}
+			replication.Repository, _, _ = unstructured.NestedString(source.Object, "spec", "restic", "repository")
+			replications[source.GetNamespace()+"/"+claimName] = replication
+		}
+	}
+
+	// the VolumeReplication operator is not necessarily installed on the Spoke
+	volumeReplications, err := r.spokeDynamic.Resource(volumeReplicationsGVR).Namespace(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, err
+	}
+	if err == nil {
+		for _, volumeReplication := range volumeReplications.Items {
+			kind, _, _ := unstructured.NestedString(volumeReplication.Object, "spec", "dataSource", "kind")
+			if kind != "PersistentVolumeClaim" {
+				continue
+			}
+			claimName, _, _ := unstructured.NestedString(volumeReplication.Object, "spec", "dataSource", "name")
+			class, _, _ := unstructured.NestedString(volumeReplication.Object, "spec", "volumeReplicationClass")
+			replications[volumeReplication.GetNamespace()+"/"+claimName] = &inventory.Replication{
+				Type:  inventory.ReplicationVolumeReplication,
+				Name:  volumeReplication.GetName(),
+				Class: class,
+			}
+		}
+	}
+
+	return replications, nil
+}
+
+// isSystemNamespace returns true if the namespace is a platform namespace that should not be part of the inventory.
+func isSystemNamespace(namespace string) bool {
+	for _, name := range systemNamespaces {
+		if namespace == name {
+			return true
+		}
+	}
+	for _, prefix := range systemNamespacePrefixes {
+		if strings.HasPrefix(namespace, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// replicasOrDefault returns the replicas value, or the K8S default of 1 if not set.
+func replicasOrDefault(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
+
+// containerImages is used for extracting the images of all the containers and init containers of a pod spec.
+func containerImages(spec corev1.PodSpec) []string {
+	var images []string
+	for _, container := range append(spec.InitContainers, spec.Containers...) {
+		images = append(images, container.Image)
+	}
+	return images
+}
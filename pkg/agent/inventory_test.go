@@ -0,0 +1,34 @@
+// Copyright (c) 2023 Red Hat, Inc.
+
+package agent
+
+import "testing"
+
+func TestIsSystemNamespace(t *testing.T) {
+	tests := []struct {
+		namespace string
+		want      bool
+	}{
+		{"default", true},
+		{"openshift", true},
+		{"openshift-monitoring", true},
+		{"kube-system", true},
+		{"hive", true},
+		{"hive-metrics", true},
+		{"open-cluster-management", true},
+		{"open-cluster-management-agent", true},
+		{"default-app", false},
+		{"openshiftdemo", false},
+		{"hivemind", false},
+		{"kubeflow", false},
+		{"my-app", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.namespace, func(t *testing.T) {
+			if got := isSystemNamespace(tt.namespace); got != tt.want {
+				t.Errorf("isSystemNamespace(%q) = %v, want %v", tt.namespace, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2023 Red Hat, Inc.
+
+package backup
+
+// This file contains types and functions for archiving objects and storing the archives in a backup target.
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"sigs.k8s.io/yaml"
+	"time"
+)
+
+// Target is used for storing backup archives.
+type Target interface {
+	// Store is used for storing data under a key, i.e. 'spoke-name/20230101T000000Z.tar.gz'. Returns the location of the
+	// stored data.
+	Store(ctx context.Context, key string, data []byte) (string, error)
+}
+
+// Archive is used for building a gzipped tar archive of objects serialized as YAML. Use NewArchive for instantiation.
+type Archive struct {
+	buffer *bytes.Buffer
+	gzip   *gzip.Writer
+	tar    *tar.Writer
+}
+
+// NewArchive is used as a factory for creating an empty Archive.
+func NewArchive() *Archive {
+	buffer := &bytes.Buffer{}
+	gzipWriter := gzip.NewWriter(buffer)
+	return &Archive{buffer: buffer, gzip: gzipWriter, tar: tar.NewWriter(gzipWriter)}
+}
+
+// Add is used for serializing an object as YAML and adding it to the archive in the given path.
+func (a *Archive) Add(path string, obj interface{}) error {
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		return err
+	}
+
+	header := &tar.Header{Name: path, Mode: 0o600, Size: int64(len(data)), ModTime: time.Now()}
+	if err = a.tar.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err = a.tar.Write(data)
+	return err
+}
+
+// Close is used for closing the archive, returns the archive content. The archive can not be added to once closed.
+func (a *Archive) Close() ([]byte, error) {
+	if err := a.tar.Close(); err != nil {
+		return nil, err
+	}
+	if err := a.gzip.Close(); err != nil {
+		return nil, err
+	}
+	return a.buffer.Bytes(), nil
+}
@@ -0,0 +1,28 @@
+// Copyright (c) 2023 Red Hat, Inc.
+
+package backup
+
+// This file contains the Target implementation for storing archives in a local directory, i.e. a mounted PVC.
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+)
+
+// DirectoryTarget is a Target storing archives in a local directory.
+type DirectoryTarget struct {
+	Path string
+}
+
+// Store is used for writing the data into a file named after the key in the target directory. Returns the file path.
+func (t *DirectoryTarget) Store(_ context.Context, key string, data []byte) (string, error) {
+	path := filepath.Join(t.Path, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return "", err
+	}
+	return path, nil
+}
@@ -0,0 +1,158 @@
+// Copyright (c) 2023 Red Hat, Inc.
+
+package backup
+
+// This file contains the Target implementation for storing archives in an S3 compatible object storage.
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// defaultS3Region is the region used for signing requests when none is set.
+const defaultS3Region = "us-east-1"
+
+// S3Target is a Target storing archives as objects in an S3 compatible bucket. Requests are signed with AWS Signature
+// Version 4, and use path-style addressing, i.e. 'https://endpoint/bucket/key'.
+type S3Target struct {
+	Endpoint string
+	Bucket   string
+	// Region is optional, defaults to defaultS3Region.
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+	// Client is optional, defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+// Store is used for uploading the data as an object named after the key. Returns the object's S3 URI.
+func (t *S3Target) Store(ctx context.Context, key string, data []byte) (string, error) {
+	endpoint, err := url.Parse(t.Endpoint)
+	if err != nil {
+		return "", err
+	}
+	endpoint.Path = fmt.Sprintf("/%s/%s", t.Bucket, key)
+	endpoint.RawPath = uriEncode(endpoint.Path)
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint.String(), bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	request.ContentLength = int64(len(data))
+	request.Header.Set("Content-Type", "application/gzip")
+	t.sign(request, data, time.Now().UTC())
+
+	client := t.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		return "", fmt.Errorf("failed storing %s, %s: %s", key, response.Status, string(body))
+	}
+	return fmt.Sprintf("s3://%s/%s", t.Bucket, key), nil
+}
+
+// sign is used for signing a request with AWS Signature Version 4, setting the required headers. The host and all the
+// headers set on the request are signed.
+func (t *S3Target) sign(request *http.Request, payload []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	dateStamp := now.Format("20060102")
+	payloadHash := hashHex(payload)
+	region := t.Region
+	if region == "" {
+		region = defaultS3Region
+	}
+
+	request.Header.Set("X-Amz-Date", amzDate)
+	request.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": request.URL.Host}
+	for name, values := range request.Header {
+		trimmed := make([]string, len(values))
+		for i, value := range values {
+			trimmed[i] = strings.Join(strings.Fields(value), " ")
+		}
+		headers[strings.ToLower(name)] = strings.Join(trimmed, ",")
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		uriEncode(request.URL.Path),
+		request.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := fmt.Sprintf("%s/%s/s3/aws4_request", dateStamp, region)
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, hashHex([]byte(canonicalRequest))}, "\n")
+
+	signature := hex.EncodeToString(hmacSHA256(deriveSigningKey(t.SecretAccessKey, dateStamp, region, "s3"), stringToSign))
+
+	request.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		t.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// deriveSigningKey is used for deriving the AWS Signature Version 4 signing key for a date, region, and service.
+func deriveSigningKey(secretAccessKey, dateStamp, region, service string) []byte {
+	key := hmacSHA256([]byte("AWS4"+secretAccessKey), dateStamp)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	return hmacSHA256(key, "aws4_request")
+}
+
+// uriEncode is used for encoding a path as required by AWS Signature Version 4, encoding every byte except for the
+// unreserved characters and the path separators.
+func uriEncode(path string) string {
+	var builder strings.Builder
+	for _, b := range []byte(path) {
+		if ('A' <= b && b <= 'Z') || ('a' <= b && b <= 'z') || ('0' <= b && b <= '9') ||
+			b == '-' || b == '_' || b == '.' || b == '~' || b == '/' {
+			builder.WriteByte(b)
+		} else {
+			builder.WriteString(fmt.Sprintf("%%%02X", b))
+		}
+	}
+	return builder.String()
+}
+
+// hashHex returns the hex encoded SHA256 hash of the data.
+func hashHex(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of the data using the key.
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
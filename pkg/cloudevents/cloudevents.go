@@ -0,0 +1,47 @@
+// Copyright (c) 2023 Red Hat, Inc.
+
+package cloudevents
+
+// This file contains the CloudEvent envelope shared by the notifiers and the exporter.
+
+import (
+	"encoding/json"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/mcra"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"time"
+)
+
+// TypePrefix prefixes the CloudEvents type attribute, i.e. 'com.redhat.ecosystem.appeng.mcra.availability.changed'.
+const TypePrefix = "com.redhat.ecosystem.appeng.mcra."
+
+// CloudEvent is a CloudEvent in its structured JSON form, sourced from the Addon.
+type CloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Subject         string          `json:"subject"`
+	Time            string          `json:"time"`
+	DataContentType string          `json:"datacontenttype"`
+	Data            json.RawMessage `json:"data"`
+}
+
+// New is used as a factory for creating a CloudEvent with a unique id, carrying the data as JSON. The eventType is
+// prefixed with TypePrefix.
+func New(eventType, subject string, eventTime time.Time, data interface{}) (CloudEvent, error) {
+	serialized, err := json.Marshal(data)
+	if err != nil {
+		return CloudEvent{}, err
+	}
+
+	return CloudEvent{
+		SpecVersion:     "1.0",
+		ID:              string(uuid.NewUUID()),
+		Source:          mcra.AddonName,
+		Type:            TypePrefix + eventType,
+		Subject:         subject,
+		Time:            eventTime.UTC().Format(time.RFC3339Nano),
+		DataContentType: "application/json",
+		Data:            serialized,
+	}, nil
+}
@@ -6,20 +6,111 @@ package actions
 
 import (
 	"context"
+	apiv1 "github.com/rhecosystemappeng/multicluster-resiliency-addon/api/v1"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/backup"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/tools/record"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+	"reflect"
+	"runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"strings"
+	"time"
 )
 
 type Options struct {
 	client.Client
+	// Reader reads directly from the API server, for kinds not to be cached by the manager, i.e. Secrets.
+	Reader                            client.Reader
+	Discovery                         discovery.DiscoveryInterface
+	Recorder                          record.EventRecorder
 	OldSpoke, NewSpoke, ConfigMapName string
+	LogicalName                       string
+	AddonsAllowList, AddonsDenyList   []string
+	MigrationRules                    []MigrationRule
+	SecretsSelector                   string
+	SecretsNames                      []string
+	ManagedClusterInclude             []string
+	ManagedClusterExclude             []string
+	BackupTarget                      backup.Target
+	BackupSecrets                     bool
+	ClusterDeploymentRetention        time.Duration
+	SmokeChecks                       []SmokeCheck
+	// Replacement is populated by the actions with facts to be recorded on the NEW spoke's ResilientCluster.
+	Replacement *apiv1.ReplacementStatus
 }
 
-// actionFuncs is used for registering actions to be performs when replacing clusters.
-var actionFuncs []func(ctx context.Context, options Options)
+// actionFunc is an action performed when replacing clusters. Actions log their failures and carry on, returning an
+// error summarizing the failures.
+type actionFunc func(ctx context.Context, options Options) error
 
-// PerformReplace is used for performing all registered actions related to replacing the cluster.
-func PerformReplace(ctx context.Context, options Options) {
+// actionFuncs lists the actions performed when replacing clusters, in order. The OLD spoke is backed up first, and the
+// actions reading from the OLD spoke's namespace run before the destructive ones deleting the OLD ManagedCluster, as
+// its namespace is removed with it.
+var actionFuncs = []actionFunc{
+	backupOldSpoke,
+	assignLogicalName,
+	assignClusterSets,
+	migrateConfigMap,
+	migrateInventory,
+	migrateManagedClusterAddon,
+	migrateManagedClusterAddons,
+	migrateAddonDeploymentConfigs,
+	migrateSecrets,
+	migrateByRules,
+	migrateManifestWorks,
+	migratePolicyPlacements,
+	migrateApplicationPlacements,
+	reportVolumeReplication,
+	applyWorkloadRestore,
+	runSmokeChecks,
+	recordPlacements,
+	// destructive actions
+	compareManagedClusterAndDeleteOld,
+	reevaluatePlacements,
+	deleteOldClusterDeployment,
+	deleteOldResilientCluster,
+}
+
+// PerformReplace is used for performing all registered actions related to replacing the cluster. Returns the outcome of
+// each action.
+func PerformReplace(ctx context.Context, options Options) []apiv1.ActionOutcome {
+	var outcomes []apiv1.ActionOutcome
+	failed := 0
 	for _, f := range actionFuncs {
-		f(ctx, options)
+		outcome := apiv1.ActionOutcome{Action: actionName(f), Succeeded: true}
+		if err := f(ctx, options); err != nil {
+			outcome.Succeeded = false
+			outcome.Message = err.Error()
+			failed++
+			recordEvent(ctx, options, corev1.EventTypeWarning, "ActionFailed", "action %s failed, %s", outcome.Action, outcome.Message)
+		}
+		outcomes = append(outcomes, outcome)
+	}
+	recordEvent(ctx, options, corev1.EventTypeNormal, "ActionsPerformed", "performed %d actions replacing %s, %d failed", len(outcomes), options.OldSpoke, failed)
+	return outcomes
+}
+
+// recordEvent is used for recording an event on the NEW spoke's ManagedCluster. Events are best-effort, nothing is
+// recorded without a Recorder or the NEW ManagedCluster.
+func recordEvent(ctx context.Context, options Options, eventType, reason, messageFmt string, args ...interface{}) {
+	if options.Recorder == nil {
+		return
 	}
+
+	newMc := &clusterv1.ManagedCluster{}
+	if err := options.Client.Get(ctx, types.NamespacedName{Name: options.NewSpoke}, newMc); err != nil {
+		log.FromContext(ctx).Info("not recording event, new ManagedCluster not found", "new-spoke", options.NewSpoke, "reason", reason)
+		return
+	}
+	options.Recorder.Eventf(newMc, eventType, reason, messageFmt, args...)
+}
+
+// actionName is used for extracting the name of an action function, i.e. 'migrateSecrets'.
+func actionName(f actionFunc) string {
+	name := runtime.FuncForPC(reflect.ValueOf(f).Pointer()).Name()
+	return name[strings.LastIndex(name, ".")+1:]
 }
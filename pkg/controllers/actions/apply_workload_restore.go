@@ -0,0 +1,107 @@
+// Copyright (c) 2023 Red Hat, Inc.
+
+package actions
+
+// This file contains the action for restoring the OLD spoke's workload on the NEW spoke from a Velero Backup.
+
+import (
+	"context"
+	"fmt"
+	apiv1 "github.com/rhecosystemappeng/multicluster-resiliency-addon/api/v1"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/mcra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	workv1 "open-cluster-management.io/api/work/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// RestorePhaseFeedback is the name of the ManifestWork status feedback reporting the Velero Restore phase.
+const RestorePhaseFeedback = "phase"
+
+// applyWorkloadRestore is used for restoring the OLD spoke's workload on the NEW spoke, by creating a ManifestWork
+// applying a Velero Restore of the latest Backup reported by the OLD spoke's Agent. The NEW spoke requires Velero
+// configured with the storage location of the OLD spoke's Backups. The ManifestWork reports the Restore phase back,
+// tracked by the RestoreReconciler. The Restore is recorded in the Replacement. Nothing is restored if the OLD spoke's
+// Agent reported no Backups.
+func applyWorkloadRestore(ctx context.Context, options Options) error {
+	logger := log.FromContext(ctx)
+	logger.Info("restoring workload", "old-spoke", options.OldSpoke, "new-spoke", options.NewSpoke)
+
+	// the backup report ConfigMap resides in the cluster-namespace
+	report := &corev1.ConfigMap{}
+	if err := options.Client.Get(ctx, types.NamespacedName{Namespace: options.OldSpoke, Name: mcra.BackupConfigMapName}, report); err != nil {
+		logger.Info("no backup reported", "old-spoke", options.OldSpoke)
+		return nil
+	}
+
+	backupName := report.Data["backup_name"]
+	veleroNamespace := report.Data["velero_namespace"]
+	if backupName == "" || veleroNamespace == "" {
+		return fmt.Errorf("backup report for %s is missing the backup_name or velero_namespace", options.OldSpoke)
+	}
+
+	restore := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "velero.io/v1",
+		"kind":       "Restore",
+		"spec": map[string]interface{}{
+			"backupName": backupName,
+			"restorePVs": true,
+		},
+	}}
+	restore.SetName(fmt.Sprintf("%s-mcra-restore", options.NewSpoke))
+	restore.SetNamespace(veleroNamespace)
+
+	work := &workv1.ManifestWork{}
+	work.SetName(mcra.RestoreManifestWorkName)
+	work.SetNamespace(options.NewSpoke)
+	work.SetLabels(map[string]string{mcra.LabelRestoreFrom: options.OldSpoke})
+	work.SetAnnotations(map[string]string{mcra.AnnotationCreatedBy: mcra.AddonName})
+	work.Spec = workv1.ManifestWorkSpec{
+		Workload: workv1.ManifestsTemplate{
+			Manifests: []workv1.Manifest{{RawExtension: runtime.RawExtension{Object: restore}}},
+		},
+		// the restored workload outlives the Restore, deleting the ManifestWork keeps the Restore for reference
+		DeleteOption: &workv1.DeleteOption{PropagationPolicy: workv1.DeletePropagationPolicyTypeOrphan},
+		ManifestConfigs: []workv1.ManifestConfigOption{{
+			ResourceIdentifier: workv1.ResourceIdentifier{
+				Group:     "velero.io",
+				Resource:  "restores",
+				Name:      restore.GetName(),
+				Namespace: restore.GetNamespace(),
+			},
+			FeedbackRules: []workv1.FeedbackRule{{
+				Type:      workv1.JSONPathsType,
+				JsonPaths: []workv1.JsonPath{{Name: RestorePhaseFeedback, Path: ".status.phase"}},
+			}},
+		}},
+	}
+
+	if err := options.Client.Create(ctx, work); err != nil {
+		if !errors.IsAlreadyExists(err) {
+			logger.Error(err, "failed creating restore ManifestWork", "new-spoke", options.NewSpoke)
+			return err
+		}
+
+		// the ManifestWork already exists in the NEW spoke, update it
+		existingWork := &workv1.ManifestWork{}
+		if err = options.Client.Get(ctx, client.ObjectKeyFromObject(work), existingWork); err != nil {
+			return err
+		}
+		existingWork.SetLabels(work.GetLabels())
+		existingWork.Spec = work.Spec
+		if err = options.Client.Update(ctx, existingWork); err != nil {
+			logger.Error(err, "failed updating restore ManifestWork", "new-spoke", options.NewSpoke)
+			return err
+		}
+	}
+	logger.Info("restore ManifestWork applied", "new-spoke", options.NewSpoke, "backup", backupName)
+
+	if options.Replacement != nil {
+		options.Replacement.Restore = &apiv1.RestoreStatus{BackupName: backupName}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2023 Red Hat, Inc.
+
+package actions
+
+// This file contains the action for moving the NEW spoke into the ManagedClusterSets of the OLD spoke.
+
+import (
+	"context"
+	"fmt"
+	"golang.org/x/exp/slices"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+	clusterv1beta2 "open-cluster-management.io/api/cluster/v1beta2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// assignClusterSets is used for making the NEW spoke a member of the same ManagedClusterSets the OLD spoke was a
+// member of, and for verifying the ManagedClusterSetBindings of said sets are bound. For exclusive sets, the clusterset
+// label is set on the NEW ManagedCluster, label selector sets are matched using the labels copied by
+// compareManagedClusterAndDeleteOld. Note, this action is required to run before the OLD ManagedCluster is deleted by
+// compareManagedClusterAndDeleteOld.
+func assignClusterSets(ctx context.Context, options Options) error {
+	logger := log.FromContext(ctx)
+	logger.Info("assigning ManagedClusterSets", "old-spoke", options.OldSpoke, "new-spoke", options.NewSpoke)
+
+	// fetch the OLD ManagedCluster or break
+	oldMc := &clusterv1.ManagedCluster{}
+	if err := options.Client.Get(ctx, types.NamespacedName{Name: options.OldSpoke}, oldMc); err != nil {
+		logger.Error(err, "failed fetching old ManagedCluster", "old-spoke", options.OldSpoke)
+		return err
+	}
+
+	// fetch the NEW ManagedCluster or break
+	newMc := &clusterv1.ManagedCluster{}
+	if err := options.Client.Get(ctx, types.NamespacedName{Name: options.NewSpoke}, newMc); err != nil {
+		logger.Error(err, "failed fetching new ManagedCluster", "new-spoke", options.NewSpoke)
+		return err
+	}
+
+	clusterSets := &clusterv1beta2.ManagedClusterSetList{}
+	if err := options.Client.List(ctx, clusterSets); err != nil {
+		logger.Error(err, "failed listing ManagedClusterSets")
+		return err
+	}
+
+	// find the sets the OLD spoke is a member of
+	var memberOf []string
+	failed := 0
+	for _, clusterSet := range clusterSets.Items {
+		selector, err := clusterv1beta2.BuildClusterSelector(&clusterSet)
+		if err != nil {
+			logger.Error(err, "failed building ManagedClusterSet selector", "cluster-set", clusterSet.Name)
+			failed++
+			continue
+		}
+		if !selector.Matches(labels.Set(oldMc.GetLabels())) {
+			continue
+		}
+		memberOf = append(memberOf, clusterSet.Name)
+
+		if clusterSet.Spec.ClusterSelector.SelectorType == clusterv1beta2.LabelSelector {
+			logger.Info("label selector ManagedClusterSet membership is based on the copied labels", "cluster-set", clusterSet.Name)
+			continue
+		}
+
+		// exclusive sets membership is set using the clusterset label
+		if newMc.GetLabels()[clusterv1beta2.ClusterSetLabel] != clusterSet.Name {
+			mcPatch := client.MergeFrom(newMc.DeepCopy())
+			newMcLabels := newMc.GetLabels()
+			if newMcLabels == nil {
+				newMcLabels = map[string]string{}
+			}
+			newMcLabels[clusterv1beta2.ClusterSetLabel] = clusterSet.Name
+			newMc.SetLabels(newMcLabels)
+
+			if err = options.Client.Patch(ctx, newMc, mcPatch); err != nil {
+				logger.Error(err, "failed assigning new ManagedCluster to ManagedClusterSet", "new-spoke", options.NewSpoke, "cluster-set", clusterSet.Name)
+				failed++
+				continue
+			}
+		}
+		logger.Info("new ManagedCluster assigned to ManagedClusterSet", "new-spoke", options.NewSpoke, "cluster-set", clusterSet.Name)
+	}
+
+	verifyClusterSetBindings(ctx, memberOf, options)
+
+	if failed > 0 {
+		return fmt.Errorf("failed assigning %d ManagedClusterSets", failed)
+	}
+	return nil
+}
+
+// verifyClusterSetBindings is used for verifying the ManagedClusterSetBindings for the given ManagedClusterSets are
+// bound, making the sets, and the NEW spoke, available for Placements in the bindings' namespaces.
+func verifyClusterSetBindings(ctx context.Context, clusterSetNames []string, options Options) {
+	logger := log.FromContext(ctx)
+
+	bindings := &clusterv1beta2.ManagedClusterSetBindingList{}
+	if err := options.Client.List(ctx, bindings); err != nil {
+		logger.Error(err, "failed listing ManagedClusterSetBindings")
+		return
+	}
+
+	for _, binding := range bindings.Items {
+		if !slices.Contains(clusterSetNames, binding.Spec.ClusterSet) {
+			continue
+		}
+
+		if meta.IsStatusConditionTrue(binding.Status.Conditions, clusterv1beta2.ClusterSetBindingBoundType) {
+			logger.Info("ManagedClusterSetBinding is bound", "cluster-set", binding.Spec.ClusterSet, "binding-namespace", binding.Namespace)
+		} else {
+			logger.Info("ManagedClusterSetBinding is not bound", "cluster-set", binding.Spec.ClusterSet, "binding-namespace", binding.Namespace)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright (c) 2023 Red Hat, Inc.
+
+package actions
+
+// This file contains the action for labeling the NEW spoke with the logical name of the cluster it replaces.
+
+import (
+	"context"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/mcra"
+	"k8s.io/apimachinery/pkg/types"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// assignLogicalName is used for setting the logical-name label on the NEW ManagedCluster, so anything addressing the
+// cluster by its logical name will follow the replacement. The LogicalName option is resolved by the ClaimReconciler,
+// it is the logical name of the OLD spoke, or the OLD spoke name for first generation clusters.
+func assignLogicalName(ctx context.Context, options Options) error {
+	logger := log.FromContext(ctx)
+	logger.Info("assigning logical name", "new-spoke", options.NewSpoke, "logical-name", options.LogicalName)
+
+	if options.LogicalName == "" {
+		logger.Info("no logical name to assign", "new-spoke", options.NewSpoke)
+		return nil
+	}
+
+	// fetch the NEW ManagedCluster or break
+	newMc := &clusterv1.ManagedCluster{}
+	if err := options.Client.Get(ctx, types.NamespacedName{Name: options.NewSpoke}, newMc); err != nil {
+		logger.Error(err, "failed fetching new ManagedCluster", "new-spoke", options.NewSpoke)
+		return err
+	}
+
+	if newMc.GetLabels()[mcra.LabelLogicalName] == options.LogicalName {
+		return nil
+	}
+
+	mcPatch := client.MergeFrom(newMc.DeepCopy())
+	newMcLabels := newMc.GetLabels()
+	if newMcLabels == nil {
+		newMcLabels = map[string]string{}
+	}
+	newMcLabels[mcra.LabelLogicalName] = options.LogicalName
+	newMc.SetLabels(newMcLabels)
+
+	if err := options.Client.Patch(ctx, newMc, mcPatch); err != nil {
+		logger.Error(err, "failed labeling new ManagedCluster", "new-spoke", options.NewSpoke, "logical-name", options.LogicalName)
+		return err
+	}
+	return nil
+}
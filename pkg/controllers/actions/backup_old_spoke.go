@@ -0,0 +1,169 @@
+// Copyright (c) 2023 Red Hat, Inc.
+
+package actions
+
+// This file contains the action for backing up the OLD spoke before it is removed by the destructive actions.
+
+import (
+	"context"
+	"fmt"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/backup"
+	"golang.org/x/exp/slices"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"path"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"time"
+)
+
+// annotationLastApplied is set by kubectl with the last applied configuration, including the values of secrets.
+const annotationLastApplied = "kubectl.kubernetes.io/last-applied-configuration"
+
+var (
+	// backupGroupResources are the namespaced resources backed up from the OLD spoke namespace, the ones created in
+	// cluster namespaces by OCM, ACM, Hive, and the addon, and the users' configuration.
+	backupGroupResources = []string{
+		"configmaps",
+		"secrets",
+		"serviceaccounts",
+		"roles.rbac.authorization.k8s.io",
+		"rolebindings.rbac.authorization.k8s.io",
+		"clusterdeployments.hive.openshift.io",
+		"machinepools.hive.openshift.io",
+		"syncsets.hive.openshift.io",
+		"managedclusterinfos.internal.open-cluster-management.io",
+		"managedclusteraddons.addon.open-cluster-management.io",
+		"addondeploymentconfigs.addon.open-cluster-management.io",
+		"manifestworks.work.open-cluster-management.io",
+		"klusterletaddonconfigs.agent.open-cluster-management.io",
+		"policies.policy.open-cluster-management.io",
+		"resilientclusters.appeng.ecosystem.redhat.com",
+	}
+
+	managedClusterGVK = schema.GroupVersionKind{Group: "cluster.open-cluster-management.io", Version: "v1", Kind: "ManagedCluster"}
+)
+
+// backupOldSpoke is used for archiving the backupGroupResources objects found in the OLD spoke namespace, including the
+// ClusterDeployment, and the OLD ManagedCluster, and storing the archive in the BackupTarget option. Secrets are
+// archived with their values redacted, unless the BackupSecrets option is set. The archive location is recorded in the
+// Replacement. Nothing is archived if the BackupTarget option is not set. Note, this action is required to run first,
+// before the destructive actions, compareManagedClusterAndDeleteOld, deleteOldClusterDeployment, and
+// deleteOldResilientCluster, these will keep the OLD spoke if the backup failed.
+func backupOldSpoke(ctx context.Context, options Options) error {
+	logger := log.FromContext(ctx)
+
+	if options.BackupTarget == nil {
+		logger.Info("no backup target configured", "old-spoke", options.OldSpoke)
+		return nil
+	}
+	logger.Info("backing up old spoke", "old-spoke", options.OldSpoke)
+
+	archive := backup.NewArchive()
+	if err := archiveNamespace(ctx, archive, options); err != nil {
+		logger.Error(err, "failed archiving old spoke namespace", "old-spoke", options.OldSpoke)
+		return err
+	}
+
+	oldMc := &unstructured.Unstructured{}
+	oldMc.SetGroupVersionKind(managedClusterGVK)
+	if err := options.Reader.Get(ctx, types.NamespacedName{Name: options.OldSpoke}, oldMc); err != nil {
+		logger.Error(err, "failed fetching old ManagedCluster", "old-spoke", options.OldSpoke)
+		return err
+	}
+	oldMc.SetManagedFields(nil)
+	if err := archive.Add("cluster/managedcluster.yaml", oldMc.Object); err != nil {
+		logger.Error(err, "failed archiving old ManagedCluster", "old-spoke", options.OldSpoke)
+		return err
+	}
+
+	data, err := archive.Close()
+	if err != nil {
+		logger.Error(err, "failed closing old spoke archive", "old-spoke", options.OldSpoke)
+		return err
+	}
+
+	key := fmt.Sprintf("%s/%s.tar.gz", options.OldSpoke, time.Now().UTC().Format("20060102T150405Z"))
+	location, err := options.BackupTarget.Store(ctx, key, data)
+	if err != nil {
+		logger.Error(err, "failed storing old spoke archive", "old-spoke", options.OldSpoke)
+		return err
+	}
+	logger.Info("old spoke backed up", "old-spoke", options.OldSpoke, "location", location)
+
+	if options.Replacement != nil {
+		options.Replacement.BackupLocation = location
+	}
+	return nil
+}
+
+// archiveNamespace is used for adding the backupGroupResources objects in the OLD spoke namespace to the archive, in
+// paths built from the group, the resource, and the object name, i.e. 'namespace/hive.openshift.io/clusterdeployments/name.yaml'.
+// The resources are discovered for their preferred versions, and listed directly from the API server.
+func archiveNamespace(ctx context.Context, archive *backup.Archive, options Options) error {
+	logger := log.FromContext(ctx)
+
+	// partial discovery is acceptable, unavailable groups are skipped
+	resourceLists, err := options.Discovery.ServerPreferredNamespacedResources()
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return err
+	}
+
+	for _, resourceList := range resourceLists {
+		gv, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil {
+			return err
+		}
+
+		for _, resource := range resourceList.APIResources {
+			groupResource := schema.GroupResource{Group: gv.Group, Resource: resource.Name}
+			if !slices.Contains(resource.Verbs, "list") || !slices.Contains(backupGroupResources, groupResource.String()) {
+				continue
+			}
+
+			objs := &unstructured.UnstructuredList{}
+			objs.SetGroupVersionKind(gv.WithKind(resource.Kind + "List"))
+			if err = options.Reader.List(ctx, objs, client.InNamespace(options.OldSpoke)); err != nil {
+				logger.Error(err, "failed listing resources for backup", "old-spoke", options.OldSpoke, "resource", groupResource.String())
+				continue
+			}
+
+			group := gv.Group
+			if group == "" {
+				group = "core"
+			}
+			for _, obj := range objs.Items {
+				obj.SetManagedFields(nil)
+				if resource.Kind == "Secret" && gv.Group == "" && !options.BackupSecrets {
+					redactSecret(&obj)
+				}
+				if err = archive.Add(path.Join("namespace", group, resource.Name, obj.GetName()+".yaml"), obj.Object); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// redactSecret is used for removing the values of a secret, keeping its keys. The last applied configuration annotation
+// is removed as well, it holds the values.
+func redactSecret(secret *unstructured.Unstructured) {
+	data, _, _ := unstructured.NestedMap(secret.Object, "data")
+	for key := range data {
+		data[key] = ""
+	}
+	if len(data) > 0 {
+		_ = unstructured.SetNestedMap(secret.Object, data, "data")
+	}
+	unstructured.RemoveNestedField(secret.Object, "stringData")
+	unstructured.RemoveNestedField(secret.Object, "metadata", "annotations", annotationLastApplied)
+}
+
+// oldSpokeNotBackedUp returns true if a backup target is configured but the OLD spoke was not backed up. The destructive
+// actions use it for keeping the OLD spoke in place.
+func oldSpokeNotBackedUp(options Options) bool {
+	return options.BackupTarget != nil && options.Replacement != nil && options.Replacement.BackupLocation == ""
+}
@@ -0,0 +1,37 @@
+// Copyright (c) 2023 Red Hat, Inc.
+
+package actions
+
+import (
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"testing"
+)
+
+func TestRedactSecret(t *testing.T) {
+	secret := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata": map[string]interface{}{
+			"name": "app-credentials",
+			"annotations": map[string]interface{}{
+				annotationLastApplied: `{"stringData":{"password":"hunter2"}}`,
+				"example.com/owner":   "payments",
+			},
+		},
+		"data":       map[string]interface{}{"password": "aHVudGVyMg==", "username": "YWRtaW4="},
+		"stringData": map[string]interface{}{"token": "abc"},
+	}}
+
+	redactSecret(secret)
+
+	if data, _, _ := unstructured.NestedMap(secret.Object, "data"); !equality.Semantic.DeepEqual(data, map[string]interface{}{"password": "", "username": ""}) {
+		t.Errorf("redactSecret() data = %v, want the keys with empty values", data)
+	}
+	if _, found := secret.Object["stringData"]; found {
+		t.Error("redactSecret() kept stringData")
+	}
+	if annotations := secret.GetAnnotations(); !equality.Semantic.DeepEqual(annotations, map[string]string{"example.com/owner": "payments"}) {
+		t.Errorf("redactSecret() annotations = %v, want only the last applied configuration removed", annotations)
+	}
+}
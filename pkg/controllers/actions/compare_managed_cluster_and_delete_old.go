@@ -6,16 +6,44 @@ package actions
 
 import (
 	"context"
+	"fmt"
+	apiv1 "github.com/rhecosystemappeng/multicluster-resiliency-addon/api/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	clusterv1 "open-cluster-management.io/api/cluster/v1"
+	"path"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sort"
 )
 
+// ocmOwnedKeys are patterns of label and annotation keys owned by OCM, ACM, and Hive, describing the actual cluster
+// rather than its role. These are never copied, regardless of the configured include patterns.
+var ocmOwnedKeys = []string{
+	"name",
+	"clusterID",
+	"vendor",
+	"cloud",
+	"region",
+	"local-cluster",
+	"openshiftVersion",
+	"openshiftVersion-*",
+	"installer.name",
+	"installer.namespace",
+	"feature.open-cluster-management.io/*",
+	"cluster.open-cluster-management.io/*",
+	"agent.open-cluster-management.io/*",
+	"import.open-cluster-management.io/*",
+	"open-cluster-management.io/*",
+	"open-cluster-management/*",
+	"hive.openshift.io/*",
+}
+
 // compareManagedClusterAndDeleteOld is used for reconciling ManagedCluster labels and annotations from the
-// ManagedCluster representing the OLD spoke into the MangedCluster representing the NEW one. When done, It deletes the
-// OLD ManagedCluster.
-func compareManagedClusterAndDeleteOld(ctx context.Context, options Options) {
+// ManagedCluster representing the OLD spoke into the MangedCluster representing the NEW one. Keys owned by OCM are never
+// copied, other keys are filtered by the ManagedClusterInclude and ManagedClusterExclude patterns options. The changes
+// made to the NEW ManagedCluster are recorded in the Replacement. When done, It deletes the OLD ManagedCluster.
+func compareManagedClusterAndDeleteOld(ctx context.Context, options Options) error {
 	logger := log.FromContext(ctx)
 	logger.Info("comparing ManagedCluster resources", "old-spoke", options.OldSpoke, "new-spoke", options.NewSpoke)
 
@@ -23,34 +51,94 @@ func compareManagedClusterAndDeleteOld(ctx context.Context, options Options) {
 	oldMc := &clusterv1.ManagedCluster{}
 	if err := options.Client.Get(ctx, types.NamespacedName{Name: options.OldSpoke}, oldMc); err != nil {
 		logger.Error(err, "failed fetching old ManagedCluster", "old-spoke", options.OldSpoke)
-		return
+		return err
 	}
 
-	// create patch object
-	mcPatch := &clusterv1.ManagedCluster{}
-	mcPatch.Annotations = oldMc.GetAnnotations()
-	mcPatch.Labels = oldMc.GetLabels()
-
 	// fetch the NEW ManagedCluster or break
 	newMc := &clusterv1.ManagedCluster{}
 	if err := options.Client.Get(ctx, types.NamespacedName{Name: options.NewSpoke}, newMc); err != nil {
 		logger.Error(err, "failed fetching new ManagedCluster", "new-spoke", options.NewSpoke)
-		return
+		return err
 	}
+	mcPatch := client.MergeFrom(newMc.DeepCopy())
+
+	// copy the allowed labels and annotations from the OLD ManagedCluster, collecting the changes
+	labels, labelChanges := mergeMetadata(newMc.GetLabels(), oldMc.GetLabels(), apiv1.MetadataLabel, options)
+	annotations, annotationChanges := mergeMetadata(newMc.GetAnnotations(), oldMc.GetAnnotations(), apiv1.MetadataAnnotation, options)
+	changes := append(labelChanges, annotationChanges...)
 
 	// patch the NEW ManageCluster with object data from the OLD ManagedCluster
-	if err := options.Client.Patch(ctx, newMc, client.StrategicMergeFrom(mcPatch)); err != nil {
-		logger.Error(err, "failed patching new ManagedCluster", "new-spoke", options.NewSpoke)
-		return
+	if len(changes) > 0 {
+		newMc.SetLabels(labels)
+		newMc.SetAnnotations(annotations)
+		if err := options.Client.Patch(ctx, newMc, mcPatch); err != nil {
+			logger.Error(err, "failed patching new ManagedCluster", "new-spoke", options.NewSpoke)
+			return err
+		}
+		logger.Info("new ManagedCluster metadata updated", "new-spoke", options.NewSpoke, "changes", len(changes))
+		recordEvent(ctx, options, corev1.EventTypeNormal, "MetadataCopied", "copied %d labels and annotations from %s", len(changes), options.OldSpoke)
+
+		if options.Replacement != nil {
+			options.Replacement.ManagedClusterChanges = append(options.Replacement.ManagedClusterChanges, changes...)
+		}
 	}
 
-	// delete the OLD ManagedCluster
+	// delete the OLD ManagedCluster, unless it was required and failed to be backed up
+	if oldSpokeNotBackedUp(options) {
+		logger.Info("keeping old ManagedCluster, old spoke was not backed up", "old-spoke", options.OldSpoke)
+		return fmt.Errorf("old spoke %s was not backed up, keeping the ManagedCluster", options.OldSpoke)
+	}
 	if err := options.Client.Delete(ctx, oldMc); err != nil {
 		logger.Error(err, "failed deleting old ManagedCluster", "old-spoke", options.OldSpoke)
+		return err
+	}
+	recordEvent(ctx, options, corev1.EventTypeNormal, "ManagedClusterDeleted", "deleted old ManagedCluster %s", options.OldSpoke)
+	return nil
+}
+
+// mergeMetadata is used for merging the allowed keys from the OLD metadata into the NEW metadata. Returns the merged
+// metadata and the changes made to the NEW metadata, sorted by key.
+func mergeMetadata(newMeta, oldMeta map[string]string, metaType apiv1.MetadataType, options Options) (map[string]string, []apiv1.MetadataChange) {
+	merged := map[string]string{}
+	for key, value := range newMeta {
+		merged[key] = value
+	}
+
+	var changes []apiv1.MetadataChange
+	for key, value := range oldMeta {
+		if !shouldCopyKey(key, options) {
+			continue
+		}
+		previous, found := newMeta[key]
+		if found && previous == value {
+			continue
+		}
+		merged[key] = value
+		changes = append(changes, apiv1.MetadataChange{Type: metaType, Key: key, Value: value, PreviousValue: previous})
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+	return merged, changes
+}
+
+// shouldCopyKey returns true if a label or annotation key should be copied from the OLD ManagedCluster to the NEW one.
+// Keys owned by OCM are never copied, excluded keys are not copied, and when include patterns are configured, only
+// included keys are copied.
+func shouldCopyKey(key string, options Options) bool {
+	if matchesAny(key, ocmOwnedKeys) || matchesAny(key, options.ManagedClusterExclude) {
+		return false
 	}
+	return len(options.ManagedClusterInclude) == 0 || matchesAny(key, options.ManagedClusterInclude)
 }
 
-// init is registering compareManagedClusterAndDeleteOld for running.
-func init() {
-	actionFuncs = append(actionFuncs, compareManagedClusterAndDeleteOld)
+// matchesAny returns true if the key matches any of the shell patterns, i.e. 'example.com/*'.
+func matchesAny(key string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, key); matched {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,63 @@
+// Copyright (c) 2023 Red Hat, Inc.
+
+package actions
+
+import (
+	apiv1 "github.com/rhecosystemappeng/multicluster-resiliency-addon/api/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"testing"
+)
+
+func TestShouldCopyKey(t *testing.T) {
+	tests := []struct {
+		name             string
+		key              string
+		include, exclude []string
+		want             bool
+	}{
+		{name: "user key copied by default", key: "env", want: true},
+		{name: "user prefixed key copied by default", key: "example.com/team", want: true},
+		{name: "cluster name is owned", key: "name", want: false},
+		{name: "cluster id is owned", key: "clusterID", want: false},
+		{name: "version is owned", key: "openshiftVersion", want: false},
+		{name: "version variant is owned", key: "openshiftVersion-major-minor", want: false},
+		{name: "feature label is owned", key: "feature.open-cluster-management.io/addon-work-manager", want: false},
+		{name: "cluster set label is owned", key: "cluster.open-cluster-management.io/clusterset", want: false},
+		{name: "ocm annotation is owned", key: "open-cluster-management/created-via", want: false},
+		{name: "hive label is owned", key: "hive.openshift.io/cluster-platform", want: false},
+		{name: "owned key ignores include", key: "region", include: []string{"*"}, want: false},
+		{name: "included key", key: "example.com/team", include: []string{"example.com/*"}, want: true},
+		{name: "not included key", key: "env", include: []string{"example.com/*"}, want: false},
+		{name: "excluded key", key: "example.com/owner", exclude: []string{"example.com/owner"}, want: false},
+		{name: "exclude wins over include", key: "example.com/owner", include: []string{"example.com/*"}, exclude: []string{"example.com/owner"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := Options{ManagedClusterInclude: tt.include, ManagedClusterExclude: tt.exclude}
+			if got := shouldCopyKey(tt.key, options); got != tt.want {
+				t.Errorf("shouldCopyKey(%q) = %v, want %v", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeMetadata(t *testing.T) {
+	newMeta := map[string]string{"name": "spoke2", "env": "dev", "tier": "gold"}
+	oldMeta := map[string]string{"name": "spoke1", "env": "prod", "tier": "gold", "team": "payments"}
+
+	merged, changes := mergeMetadata(newMeta, oldMeta, apiv1.MetadataLabel, Options{})
+
+	wantMerged := map[string]string{"name": "spoke2", "env": "prod", "tier": "gold", "team": "payments"}
+	if !equality.Semantic.DeepEqual(merged, wantMerged) {
+		t.Errorf("mergeMetadata() merged = %v, want %v", merged, wantMerged)
+	}
+
+	wantChanges := []apiv1.MetadataChange{
+		{Type: apiv1.MetadataLabel, Key: "env", Value: "prod", PreviousValue: "dev"},
+		{Type: apiv1.MetadataLabel, Key: "team", Value: "payments"},
+	}
+	if !equality.Semantic.DeepEqual(changes, wantChanges) {
+		t.Errorf("mergeMetadata() changes = %v, want %v", changes, wantChanges)
+	}
+}
@@ -2,20 +2,34 @@
 
 package actions
 
-// This file contains the action for deleting the ClusterDeployment from the OLD spoke.
+// This file contains the action for deleting, or retaining, the ClusterDeployment from the OLD spoke.
 
 import (
 	"context"
+	"fmt"
 	hivev1 "github.com/openshift/hive/apis/hive/v1"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/mcra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"time"
 )
 
-// deleteOldClusterDeployment is used for deleting Hive's ClusterDeployment from the OLD spoke.
-func deleteOldClusterDeployment(ctx context.Context, options Options) {
+// deleteOldClusterDeployment is used for deleting Hive's ClusterDeployment from the OLD spoke. If the
+// ClusterDeploymentRetention option is set, the ClusterDeployment is retained for forensics instead, it is hibernated,
+// labeled as retained, and annotated with the time it should be deleted by the RetentionReconciler. The retention
+// expiry is recorded in the Replacement.
+func deleteOldClusterDeployment(ctx context.Context, options Options) error {
 	logger := log.FromContext(ctx)
 	logger.Info("deleting old cluster deployment", "old-spoke", options.OldSpoke)
 
+	if oldSpokeNotBackedUp(options) {
+		logger.Info("keeping old ClusterDeployment, old spoke was not backed up", "old-spoke", options.OldSpoke)
+		return fmt.Errorf("old spoke %s was not backed up, keeping the ClusterDeployment", options.OldSpoke)
+	}
+
 	// the ClusterDeployment resides in the cluster-namespace with a matching name
 	oldDeploymentSubject := types.NamespacedName{
 		Namespace: options.OldSpoke,
@@ -26,14 +40,54 @@ func deleteOldClusterDeployment(ctx context.Context, options Options) {
 	oldDeployment := &hivev1.ClusterDeployment{}
 	if err := options.Client.Get(ctx, oldDeploymentSubject, oldDeployment); err != nil {
 		logger.Info("no ClusterDeployments found", "old-spoke", options.OldSpoke)
-	} else {
-		if err = options.Client.Delete(ctx, oldDeployment); err != nil {
-			logger.Error(err, "failed deleting ClusterDeployment", "old-spoke", options.OldSpoke)
-		}
+		return nil
 	}
+
+	if options.ClusterDeploymentRetention > 0 {
+		return retainClusterDeployment(ctx, oldDeployment, options)
+	}
+
+	if err := options.Client.Delete(ctx, oldDeployment); err != nil {
+		logger.Error(err, "failed deleting ClusterDeployment", "old-spoke", options.OldSpoke)
+		return err
+	}
+	recordEvent(ctx, options, corev1.EventTypeNormal, "ClusterDeploymentDeleted", "deleted old ClusterDeployment %s", options.OldSpoke)
+	return nil
 }
 
-// init is registering deleteOldClusterDeployment for running.
-func init() {
-	actionFuncs = append(actionFuncs, deleteOldClusterDeployment)
+// retainClusterDeployment is used for hibernating the OLD ClusterDeployment, and marking it for deletion when the
+// ClusterDeploymentRetention option expires.
+func retainClusterDeployment(ctx context.Context, deployment *hivev1.ClusterDeployment, options Options) error {
+	logger := log.FromContext(ctx)
+
+	retainUntil := metav1.NewTime(time.Now().Add(options.ClusterDeploymentRetention).UTC().Truncate(time.Second))
+	deploymentPatch := client.MergeFrom(deployment.DeepCopy())
+
+	labels := deployment.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[mcra.LabelRetained] = "true"
+	deployment.SetLabels(labels)
+
+	annotations := deployment.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[mcra.AnnotationRetainUntil] = retainUntil.Format(time.RFC3339)
+	deployment.SetAnnotations(annotations)
+
+	deployment.Spec.PowerState = hivev1.ClusterPowerStateHibernating
+
+	if err := options.Client.Patch(ctx, deployment, deploymentPatch); err != nil {
+		logger.Error(err, "failed retaining ClusterDeployment", "old-spoke", options.OldSpoke)
+		return err
+	}
+	logger.Info("old ClusterDeployment hibernated and retained", "old-spoke", options.OldSpoke, "retain-until", retainUntil)
+	recordEvent(ctx, options, corev1.EventTypeNormal, "ClusterDeploymentRetained", "hibernated old ClusterDeployment %s, retained until %s", options.OldSpoke, retainUntil.Format(time.RFC3339))
+
+	if options.Replacement != nil {
+		options.Replacement.RetainedUntil = &retainUntil
+	}
+	return nil
 }
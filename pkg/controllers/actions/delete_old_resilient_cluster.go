@@ -4,7 +4,9 @@ package actions
 
 import (
 	"context"
+	"fmt"
 	addonv1 "github.com/rhecosystemappeng/multicluster-resiliency-addon/api/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
@@ -12,10 +14,15 @@ import (
 // This file contains the action for deleting the ResilientCluster from the OLD spoke.
 
 // deleteOldResilientCluster is used for deleting Hive's ClusterDeployment from the OLD spoke.
-func deleteOldResilientCluster(ctx context.Context, options Options) {
+func deleteOldResilientCluster(ctx context.Context, options Options) error {
 	logger := log.FromContext(ctx)
 	logger.Info("deleting old resilient cluster", "old-spoke", options.OldSpoke)
 
+	if oldSpokeNotBackedUp(options) {
+		logger.Info("keeping old ResilientCluster, old spoke was not backed up", "old-spoke", options.OldSpoke)
+		return fmt.Errorf("old spoke %s was not backed up, keeping the ResilientCluster", options.OldSpoke)
+	}
+
 	// the ResilientCluster resides in the cluster-namespace with a matching name
 	oldRCSubject := types.NamespacedName{
 		Namespace: options.OldSpoke,
@@ -29,11 +36,9 @@ func deleteOldResilientCluster(ctx context.Context, options Options) {
 	} else {
 		if err = options.Client.Delete(ctx, oldRC); err != nil {
 			logger.Error(err, "failed deleting ResilientCluster", "old-spoke", options.OldSpoke)
+			return err
 		}
+		recordEvent(ctx, options, corev1.EventTypeNormal, "ResilientClusterDeleted", "deleted old ResilientCluster %s", options.OldSpoke)
 	}
-}
-
-// init is registering deleteOldResilientCluster for running.
-func init() {
-	actionFuncs = append(actionFuncs, deleteOldResilientCluster)
+	return nil
 }
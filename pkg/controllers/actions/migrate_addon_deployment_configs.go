@@ -6,14 +6,19 @@ package actions
 
 import (
 	"context"
+	"fmt"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/mcra"
+	"golang.org/x/exp/maps"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
 	addonv1alpha1 "open-cluster-management.io/api/addon/v1alpha1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 // migrateAddonDeploymentConfigs is used for moving all AddonDeploymentConfig resources found from the OLD spoke namespace
-// to the NEW one, and delete the old ones.
-func migrateAddonDeploymentConfigs(ctx context.Context, options Options) {
+// to the NEW one. Each OLD config is deleted only after its copy was verified in the NEW spoke.
+func migrateAddonDeploymentConfigs(ctx context.Context, options Options) error {
 	logger := log.FromContext(ctx)
 	logger.Info("migrating AddOnDeploymentConfig resources", "old-spoke", options.OldSpoke, "new-spoke", options.NewSpoke)
 
@@ -21,24 +26,64 @@ func migrateAddonDeploymentConfigs(ctx context.Context, options Options) {
 	oldConfigs := &addonv1alpha1.AddOnDeploymentConfigList{}
 	if err := options.Client.List(ctx, oldConfigs, &client.ListOptions{Namespace: options.OldSpoke}); err != nil {
 		logger.Info("no AddOnDeploymentConfigs found", "old-spoke", options.OldSpoke)
-	} else {
-		// iterate over all found configs, create new ones for the NEW spoke, and delete the OLD ones
-		for _, oldConfig := range oldConfigs.Items {
-			newConfig := oldConfig.DeepCopy()
-			newConfig.SetName(oldConfig.Name)
-			newConfig.SetNamespace(options.NewSpoke)
-
-			if err = options.Client.Create(ctx, newConfig); err != nil {
-				logger.Error(err, "failed creating new AddOnDeploymentConfig", "new-spoke", options.NewSpoke, "config-name", newConfig.Name)
-			}
-			if err = options.Client.Delete(ctx, &oldConfig); err != nil {
-				logger.Error(err, "failed deleting AddOnDeploymentConfig", "old-spoke", options.OldSpoke, "config-name", oldConfig.Name)
-			}
+		return nil
+	}
+
+	failed := 0
+	// iterate over all found configs, copy them to the NEW spoke, and delete the OLD ones
+	for _, oldConfig := range oldConfigs.Items {
+		if err := copyAddonDeploymentConfig(ctx, &oldConfig, options); err != nil {
+			logger.Error(err, "failed migrating AddOnDeploymentConfig, keeping the old one", "new-spoke", options.NewSpoke, "config-name", oldConfig.Name)
+			failed++
+			continue
+		}
+
+		if err := options.Client.Delete(ctx, &oldConfig); err != nil {
+			logger.Error(err, "failed deleting AddOnDeploymentConfig", "old-spoke", options.OldSpoke, "config-name", oldConfig.Name)
+			failed++
 		}
 	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed migrating %d AddOnDeploymentConfigs", failed)
+	}
+	return nil
 }
 
-// init is registering migrateAddonDeploymentConfigs for running.
-func init() {
-	actionFuncs = append(actionFuncs, migrateAddonDeploymentConfigs)
+// copyAddonDeploymentConfig is used for creating a copy of an AddOnDeploymentConfig in the NEW spoke namespace, or
+// updating an existing one, and verifying the copy spec. Only the name, labels, annotations, and spec are copied.
+func copyAddonDeploymentConfig(ctx context.Context, oldConfig *addonv1alpha1.AddOnDeploymentConfig, options Options) error {
+	annotations := map[string]string{}
+	maps.Copy(annotations, oldConfig.GetAnnotations())
+	annotations[mcra.AnnotationCreatedBy] = mcra.AddonName
+	annotations[mcra.AnnotationFromAnnotation] = options.OldSpoke
+
+	newConfig := &addonv1alpha1.AddOnDeploymentConfig{}
+	newConfig.SetName(oldConfig.Name)
+	newConfig.SetNamespace(options.NewSpoke)
+	newConfig.SetLabels(oldConfig.GetLabels())
+	newConfig.SetAnnotations(annotations)
+	oldConfig.Spec.DeepCopyInto(&newConfig.Spec)
+
+	if err := options.Client.Create(ctx, newConfig); err != nil {
+		if !errors.IsAlreadyExists(err) {
+			return err
+		}
+
+		// the copy already exists in the NEW spoke, update it
+		existingConfig := &addonv1alpha1.AddOnDeploymentConfig{}
+		if err = options.Reader.Get(ctx, client.ObjectKeyFromObject(newConfig), existingConfig); err != nil {
+			return err
+		}
+		newConfig.SetResourceVersion(existingConfig.GetResourceVersion())
+		if err = options.Client.Update(ctx, newConfig); err != nil {
+			return err
+		}
+	}
+
+	// verify the copy returned by the server before the caller deletes the original
+	if !equality.Semantic.DeepEqual(newConfig.Spec, oldConfig.Spec) {
+		return fmt.Errorf("copied AddOnDeploymentConfig %s spec differs from the original", newConfig.Name)
+	}
+	return nil
 }
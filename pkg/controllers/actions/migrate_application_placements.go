@@ -0,0 +1,202 @@
+// Copyright (c) 2023 Red Hat, Inc.
+
+package actions
+
+// This file contains the action for moving application Subscriptions and ArgoCD cluster registrations to the NEW spoke.
+
+import (
+	"context"
+	"fmt"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// labelArgoSecretType is the label ArgoCD uses for identifying cluster secrets
+	labelArgoSecretType = "argocd.argoproj.io/secret-type"
+	// labelAcmCluster is the label set by the GitOpsCluster controller on the ArgoCD cluster secrets it creates
+	labelAcmCluster = "apps.open-cluster-management.io/acm-cluster"
+	// annotationHostingSubscription is set on Subscriptions propagated from a hub Subscription
+	annotationHostingSubscription = "apps.open-cluster-management.io/hosting-subscription"
+	// defaultArgoNamespace is the ArgoCD namespace used by GitOpsClusters not specifying one
+	defaultArgoNamespace = "openshift-gitops"
+)
+
+var (
+	gitOpsClusterListGVK = schema.GroupVersionKind{Group: "apps.open-cluster-management.io", Version: "v1beta1", Kind: "GitOpsClusterList"}
+	subscriptionListGVK  = schema.GroupVersionKind{Group: "apps.open-cluster-management.io", Version: "v1", Kind: "SubscriptionList"}
+	// argoNamespacesWithRBAC are the ArgoCD namespaces the argocd RBAC overlay grants deleting Secrets in
+	argoNamespacesWithRBAC = map[string]bool{defaultArgoNamespace: true}
+)
+
+// migrateApplicationPlacements is used for moving applications targeting the OLD spoke by name to the NEW spoke. The
+// Placements and PlacementRules referenced by GitOpsClusters and Subscriptions are rewritten, as well as Subscriptions'
+// inline cluster lists and selectors. The GitOpsCluster controller registers the NEW spoke with ArgoCD once the rewritten
+// placements' decisions change, and the ArgoCD cluster secrets created for the OLD spoke are deleted so ApplicationSets
+// will stop targeting it.
+func migrateApplicationPlacements(ctx context.Context, options Options) error {
+	logger := log.FromContext(ctx)
+	logger.Info("migrating application placements", "old-spoke", options.OldSpoke, "new-spoke", options.NewSpoke)
+
+	failed := 0
+	gitOpsClusters := &unstructured.UnstructuredList{}
+	gitOpsClusters.SetGroupVersionKind(gitOpsClusterListGVK)
+	if err := options.Client.List(ctx, gitOpsClusters); err != nil {
+		if meta.IsNoMatchError(err) {
+			logger.Info("GitOpsClusters not installed, skipping")
+		} else {
+			logger.Error(err, "failed listing GitOpsClusters")
+			failed++
+		}
+	}
+
+	failed += migrateGitOpsClusters(ctx, gitOpsClusters.Items, options) + migrateSubscriptions(ctx, options) +
+		deleteArgoClusterSecrets(ctx, gitOpsClusters.Items, options)
+	if failed > 0 {
+		return fmt.Errorf("failed migrating %d application resources", failed)
+	}
+	return nil
+}
+
+// migrateGitOpsClusters is used for rewriting the placements referenced by GitOpsClusters. The GitOpsCluster controller
+// watches the PlacementDecisions, reconciling the GitOpsClusters once the placements are rescheduled. Returns the number
+// of failures.
+func migrateGitOpsClusters(ctx context.Context, gitOpsClusters []unstructured.Unstructured, options Options) int {
+	logger := log.FromContext(ctx)
+
+	failed := 0
+	for _, gitOpsCluster := range gitOpsClusters {
+		refKind, _, _ := unstructured.NestedString(gitOpsCluster.Object, "spec", "placementRef", "kind")
+		refName, _, _ := unstructured.NestedString(gitOpsCluster.Object, "spec", "placementRef", "name")
+		refSubject := types.NamespacedName{Namespace: gitOpsCluster.GetNamespace(), Name: refName}
+
+		modified, err := rewritePlacementRef(ctx, refSubject, refKind, options)
+		if err != nil {
+			logger.Error(err, "failed rewriting GitOpsCluster placement", "gitops-cluster", gitOpsCluster.GetName(), "placement", refSubject.String())
+			failed++
+			continue
+		}
+		if modified {
+			logger.Info("GitOpsCluster migrated to new spoke", "new-spoke", options.NewSpoke, "gitops-cluster", gitOpsCluster.GetName())
+		}
+	}
+	return failed
+}
+
+// migrateSubscriptions is used for rewriting the Subscriptions' placements, both the referenced ones and the inline
+// cluster lists and selectors. Returns the number of failures.
+func migrateSubscriptions(ctx context.Context, options Options) int {
+	logger := log.FromContext(ctx)
+
+	subscriptions := &unstructured.UnstructuredList{}
+	subscriptions.SetGroupVersionKind(subscriptionListGVK)
+	if err := options.Client.List(ctx, subscriptions); err != nil {
+		if meta.IsNoMatchError(err) {
+			logger.Info("Subscriptions not installed, skipping")
+			return 0
+		}
+		logger.Error(err, "failed listing Subscriptions")
+		return 1
+	}
+
+	failed := 0
+	for _, subscription := range subscriptions.Items {
+		// propagated Subscriptions are handled by their hosting Subscription
+		if _, propagated := subscription.GetAnnotations()[annotationHostingSubscription]; propagated {
+			continue
+		}
+
+		modified := false
+		if refName, found, _ := unstructured.NestedString(subscription.Object, "spec", "placement", "placementRef", "name"); found {
+			refKind, _, _ := unstructured.NestedString(subscription.Object, "spec", "placement", "placementRef", "kind")
+			refSubject := types.NamespacedName{Namespace: subscription.GetNamespace(), Name: refName}
+
+			var err error
+			if modified, err = rewritePlacementRef(ctx, refSubject, refKind, options); err != nil {
+				logger.Error(err, "failed rewriting Subscription placement", "subscription", subscription.GetName(), "placement", refSubject.String())
+				failed++
+				continue
+			}
+		}
+
+		subscriptionPatch := client.MergeFrom(subscription.DeepCopy())
+		inlineModified, err := rewriteClusterTargets(&subscription, options, "spec", "placement")
+		if err != nil {
+			logger.Error(err, "failed rewriting Subscription inline placement", "subscription", subscription.GetName())
+			failed++
+			continue
+		}
+		if inlineModified {
+			if err = options.Client.Patch(ctx, &subscription, subscriptionPatch); err != nil {
+				logger.Error(err, "failed patching Subscription", "subscription", subscription.GetName())
+				failed++
+				continue
+			}
+		}
+
+		if modified || inlineModified {
+			logger.Info("Subscription migrated to new spoke", "new-spoke", options.NewSpoke, "subscription", types.NamespacedName{Namespace: subscription.GetNamespace(), Name: subscription.GetName()}.String())
+		}
+	}
+	return failed
+}
+
+// deleteArgoClusterSecrets is used for deleting the ArgoCD cluster secrets created by the GitOpsCluster controller for
+// the OLD spoke. Only the ArgoCD namespaces of the GitOpsClusters are listed, reading directly from the API server so
+// Secrets are not cached. ArgoCD namespaces not in argoNamespacesWithRBAC are skipped, their secrets are left for the
+// user to delete. Returns the number of failures.
+func deleteArgoClusterSecrets(ctx context.Context, gitOpsClusters []unstructured.Unstructured, options Options) int {
+	logger := log.FromContext(ctx)
+
+	argoNamespaces := map[string]bool{}
+	for _, gitOpsCluster := range gitOpsClusters {
+		argoNamespace, _, _ := unstructured.NestedString(gitOpsCluster.Object, "spec", "argoServer", "argoNamespace")
+		if argoNamespace == "" {
+			argoNamespace = defaultArgoNamespace
+		}
+		if !argoNamespacesWithRBAC[argoNamespace] {
+			logger.Info("skipping ArgoCD cluster secrets, namespace not granted", "old-spoke", options.OldSpoke, "namespace", argoNamespace)
+			continue
+		}
+		argoNamespaces[argoNamespace] = true
+	}
+
+	failed := 0
+	for argoNamespace := range argoNamespaces {
+		failed += deleteArgoClusterSecretsIn(ctx, argoNamespace, options)
+	}
+	return failed
+}
+
+// deleteArgoClusterSecretsIn is used for deleting the ArgoCD cluster secrets for the OLD spoke in an ArgoCD namespace.
+// Returns the number of failures.
+func deleteArgoClusterSecretsIn(ctx context.Context, argoNamespace string, options Options) int {
+	logger := log.FromContext(ctx)
+
+	secrets := &corev1.SecretList{}
+	if err := options.Reader.List(ctx, secrets, client.InNamespace(argoNamespace),
+		client.MatchingLabels{labelArgoSecretType: "cluster", labelAcmCluster: "true"}); err != nil {
+		logger.Error(err, "failed listing ArgoCD cluster secrets", "namespace", argoNamespace)
+		return 1
+	}
+
+	failed := 0
+	for _, secret := range secrets.Items {
+		if string(secret.Data["name"]) != options.OldSpoke {
+			continue
+		}
+
+		if err := options.Client.Delete(ctx, &secret); err != nil {
+			logger.Error(err, "failed deleting ArgoCD cluster secret", "old-spoke", options.OldSpoke, "secret", secret.Name)
+			failed++
+			continue
+		}
+		logger.Info("deleted ArgoCD cluster secret", "old-spoke", options.OldSpoke, "secret", secret.Name)
+	}
+	return failed
+}
@@ -0,0 +1,215 @@
+// Copyright (c) 2023 Red Hat, Inc.
+
+package actions
+
+// This file contains the generic action for copying resources between spokes based on configurable rules.
+
+import (
+	"context"
+	"fmt"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/mcra"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"strings"
+)
+
+// MigrationRule describes a kind of resources to be copied from the OLD spoke namespace to the NEW one, and the
+// transformations to apply to the copies. Field paths are dot-separated, i.e. 'spec.clusterName'.
+type MigrationRule struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	// Names optionally restricts the rule to the named resources.
+	Names []string `json:"names,omitempty"`
+	// LabelSelector optionally restricts the rule to resources matching the selector.
+	LabelSelector *metav1.LabelSelector `json:"labelSelector,omitempty"`
+	// StripFields lists fields to be removed from the copies.
+	StripFields []string `json:"stripFields,omitempty"`
+	// RewriteFields lists string fields in which the OLD spoke name will be replaced with the NEW one.
+	RewriteFields []string `json:"rewriteFields,omitempty"`
+	// Rename maps original resource names to the names to use for the copies.
+	Rename map[string]string `json:"rename,omitempty"`
+	// DeleteSource will delete the original resources once successfully copied and verified.
+	DeleteSource bool `json:"deleteSource,omitempty"`
+}
+
+// migrateByRules is used for copying resources from the OLD spoke namespace to the NEW one based on the MigrationRules
+// option. Only the name, labels, annotations, and content of the resources are copied, the status and the server
+// generated metadata are dropped. Existing copies are updated. Rules for Secrets are refused, Secrets are copied by
+// migrateSecrets only. The resources are read directly from the API server, the rules' kinds are not cached.
+func migrateByRules(ctx context.Context, options Options) error {
+	logger := log.FromContext(ctx)
+	logger.Info("migrating resources by rules", "old-spoke", options.OldSpoke, "new-spoke", options.NewSpoke, "rules", len(options.MigrationRules))
+
+	failed := 0
+	for _, rule := range options.MigrationRules {
+		gvk := schema.FromAPIVersionAndKind(rule.APIVersion, rule.Kind)
+		if gvk.Group == "" && gvk.Kind == "Secret" {
+			logger.Error(fmt.Errorf("secrets are copied by the secrets selection only"), "refusing rule", "kind", gvk.String())
+			failed++
+			continue
+		}
+
+		listOptions := &client.ListOptions{Namespace: options.OldSpoke}
+		if rule.LabelSelector != nil {
+			selector, err := metav1.LabelSelectorAsSelector(rule.LabelSelector)
+			if err != nil {
+				logger.Error(err, "failed parsing rule label selector", "kind", gvk.String())
+				failed++
+				continue
+			}
+			listOptions.LabelSelector = selector
+		}
+
+		oldObjs := &unstructured.UnstructuredList{}
+		oldObjs.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := options.Reader.List(ctx, oldObjs, listOptions); err != nil {
+			logger.Error(err, "failed listing resources", "old-spoke", options.OldSpoke, "kind", gvk.String())
+			failed++
+			continue
+		}
+
+		for _, oldObj := range oldObjs.Items {
+			if len(rule.Names) > 0 && !slices.Contains(rule.Names, oldObj.GetName()) {
+				continue
+			}
+
+			newObj, err := copyByRule(ctx, &oldObj, rule, options)
+			if err != nil {
+				logger.Error(err, "failed copying resource", "new-spoke", options.NewSpoke, "kind", gvk.String(), "name", oldObj.GetName())
+				failed++
+				continue
+			}
+			logger.Info("copied resource", "new-spoke", options.NewSpoke, "kind", gvk.String(), "name", oldObj.GetName())
+
+			if rule.DeleteSource {
+				// verify the copy stored by the server before deleting the original
+				copiedObj := &unstructured.Unstructured{}
+				copiedObj.SetGroupVersionKind(gvk)
+				if err = options.Reader.Get(ctx, client.ObjectKeyFromObject(newObj), copiedObj); err == nil {
+					err = verifyRuleCopy(newObj, copiedObj)
+				}
+				if err != nil {
+					logger.Error(err, "keeping the original resource", "old-spoke", options.OldSpoke, "kind", gvk.String(), "name", oldObj.GetName())
+					failed++
+					continue
+				}
+
+				if err = options.Client.Delete(ctx, &oldObj); err != nil {
+					logger.Error(err, "failed deleting resource", "old-spoke", options.OldSpoke, "kind", gvk.String(), "name", oldObj.GetName())
+					failed++
+				}
+			}
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed migrating %d resources by rules", failed)
+	}
+	return nil
+}
+
+// copyByRule is used for creating a transformed copy of a resource in the NEW spoke namespace based on a rule. If the
+// copy already exists, it will be updated. Returns the copy as sent to the server.
+func copyByRule(ctx context.Context, oldObj *unstructured.Unstructured, rule MigrationRule, options Options) (*unstructured.Unstructured, error) {
+	newObj := &unstructured.Unstructured{Object: oldObj.DeepCopy().Object}
+
+	// drop the server generated metadata and the status
+	delete(newObj.Object, "metadata")
+	delete(newObj.Object, "status")
+
+	name := oldObj.GetName()
+	if newName, found := rule.Rename[name]; found {
+		name = newName
+	}
+	newObj.SetName(name)
+	newObj.SetNamespace(options.NewSpoke)
+	newObj.SetLabels(oldObj.GetLabels())
+
+	annotations := map[string]string{}
+	maps.Copy(annotations, oldObj.GetAnnotations())
+	annotations[mcra.AnnotationCreatedBy] = mcra.AddonName
+	annotations[mcra.AnnotationFromAnnotation] = options.OldSpoke
+	newObj.SetAnnotations(annotations)
+
+	for _, field := range rule.StripFields {
+		unstructured.RemoveNestedField(newObj.Object, strings.Split(field, ".")...)
+	}
+
+	for _, field := range rule.RewriteFields {
+		path := strings.Split(field, ".")
+		if value, found, _ := unstructured.NestedString(newObj.Object, path...); found {
+			if err := unstructured.SetNestedField(newObj.Object, strings.ReplaceAll(value, options.OldSpoke, options.NewSpoke), path...); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// the server response is decoded into the object sent, keep the copy as sent for verification
+	err := options.Client.Create(ctx, newObj.DeepCopy())
+	if !errors.IsAlreadyExists(err) {
+		return newObj, err
+	}
+
+	// the copy already exists in the NEW spoke, update it
+	existingObj := &unstructured.Unstructured{}
+	existingObj.SetGroupVersionKind(oldObj.GroupVersionKind())
+	if err = options.Reader.Get(ctx, client.ObjectKeyFromObject(newObj), existingObj); err != nil {
+		return newObj, err
+	}
+	updateObj := newObj.DeepCopy()
+	updateObj.SetResourceVersion(existingObj.GetResourceVersion())
+	return newObj, options.Client.Update(ctx, updateObj)
+}
+
+// verifyRuleCopy is used for verifying a copy fetched from the server holds the name, labels, annotations, and content
+// of the copy sent. Fields added by the server, i.e. defaults, status, and generated metadata, are ignored.
+func verifyRuleCopy(sentObj, copiedObj *unstructured.Unstructured) error {
+	if copiedObj.GetName() != sentObj.GetName() || copiedObj.GetNamespace() != sentObj.GetNamespace() {
+		return fmt.Errorf("copy %s/%s not found", sentObj.GetNamespace(), sentObj.GetName())
+	}
+	for _, field := range []string{"labels", "annotations"} {
+		sentField, _, _ := unstructured.NestedFieldNoCopy(sentObj.Object, "metadata", field)
+		copiedField, _, _ := unstructured.NestedFieldNoCopy(copiedObj.Object, "metadata", field)
+		if !containsFields(copiedField, sentField) {
+			return fmt.Errorf("copy %s %s differ from the original", sentObj.GetName(), field)
+		}
+	}
+	for key, value := range sentObj.Object {
+		if key == "metadata" || key == "apiVersion" || key == "kind" {
+			continue
+		}
+		if !containsFields(copiedObj.Object[key], value) {
+			return fmt.Errorf("copy %s content differs from the original at %s", sentObj.GetName(), key)
+		}
+	}
+	return nil
+}
+
+// containsFields is used for checking an unstructured value holds all the fields of the wanted one, nested maps may
+// hold additional fields. A nil wanted value holds no fields.
+func containsFields(value, wanted interface{}) bool {
+	if wanted == nil {
+		return true
+	}
+	wantedMap, isMap := wanted.(map[string]interface{})
+	if !isMap {
+		return equality.Semantic.DeepEqual(value, wanted)
+	}
+	valueMap, isMap := value.(map[string]interface{})
+	if !isMap {
+		return false
+	}
+	for key, wantedValue := range wantedMap {
+		if !containsFields(valueMap[key], wantedValue) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2023 Red Hat, Inc.
+
+package actions
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"testing"
+)
+
+func TestVerifyRuleCopy(t *testing.T) {
+	sentObj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.com/v1",
+		"kind":       "Widget",
+		"metadata": map[string]interface{}{
+			"name":        "widget",
+			"namespace":   "spoke2",
+			"labels":      map[string]interface{}{"tier": "gold"},
+			"annotations": map[string]interface{}{"multicluster-resiliency-addon/copied-from": "spoke1"},
+		},
+		"spec": map[string]interface{}{"clusterName": "spoke2", "replicas": int64(2)},
+	}}
+	copied := func(mutate func(obj *unstructured.Unstructured)) *unstructured.Unstructured {
+		obj := sentObj.DeepCopy()
+		obj.SetResourceVersion("1")
+		obj.Object["status"] = map[string]interface{}{"ready": true}
+		mutate(obj)
+		return obj
+	}
+
+	tests := []struct {
+		name      string
+		copiedObj *unstructured.Unstructured
+		wantErr   bool
+	}{
+		{
+			name:      "identical copy",
+			copiedObj: copied(func(obj *unstructured.Unstructured) {}),
+		},
+		{
+			name: "server defaulted fields",
+			copiedObj: copied(func(obj *unstructured.Unstructured) {
+				_ = unstructured.SetNestedField(obj.Object, "Always", "spec", "pullPolicy")
+				obj.SetLabels(map[string]string{"tier": "gold", "env": "prod"})
+			}),
+		},
+		{
+			name: "rewritten field differs",
+			copiedObj: copied(func(obj *unstructured.Unstructured) {
+				_ = unstructured.SetNestedField(obj.Object, "spoke1", "spec", "clusterName")
+			}),
+			wantErr: true,
+		},
+		{
+			name: "stripped content",
+			copiedObj: copied(func(obj *unstructured.Unstructured) {
+				unstructured.RemoveNestedField(obj.Object, "spec", "replicas")
+			}),
+			wantErr: true,
+		},
+		{
+			name: "missing label",
+			copiedObj: copied(func(obj *unstructured.Unstructured) {
+				obj.SetLabels(nil)
+			}),
+			wantErr: true,
+		},
+		{
+			name: "missing annotation",
+			copiedObj: copied(func(obj *unstructured.Unstructured) {
+				obj.SetAnnotations(map[string]string{"other": "value"})
+			}),
+			wantErr: true,
+		},
+		{
+			name: "different name",
+			copiedObj: copied(func(obj *unstructured.Unstructured) {
+				obj.SetName("other-widget")
+			}),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := verifyRuleCopy(sentObj, tt.copiedObj); (err != nil) != tt.wantErr {
+				t.Errorf("verifyRuleCopy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
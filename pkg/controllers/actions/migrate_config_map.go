@@ -6,13 +6,20 @@ package actions
 
 import (
 	"context"
+	"fmt"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/mcra"
+	"golang.org/x/exp/maps"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
-// migrateConfigMap is used for moving the Addon's ConfigMap from the OLD spoke to the NEW one.
-func migrateConfigMap(ctx context.Context, options Options) {
+// migrateConfigMap is used for moving the Addon's ConfigMap from the OLD spoke to the NEW one. The OLD ConfigMap is
+// deleted only after its copy was verified in the NEW spoke.
+func migrateConfigMap(ctx context.Context, options Options) error {
 	logger := log.FromContext(ctx)
 	logger.Info("migrating ConfigMap resource", "old-spoke", options.OldSpoke, "new-spoke", options.NewSpoke, "config-name", options.ConfigMapName)
 
@@ -26,28 +33,58 @@ func migrateConfigMap(ctx context.Context, options Options) {
 	oldConfig := &corev1.ConfigMap{}
 	if err := options.Client.Get(ctx, oldConfigSubject, oldConfig); err != nil {
 		logger.Info("no ConfigMap found", "old-spoke", options.OldSpoke, "config-name", options.ConfigMapName)
-	} else {
-		// create new config for NEW spoke and delete the OLD one
-		newConfig := oldConfig.DeepCopy()
-
-		newConfig.SetName(options.ConfigMapName)
-		newConfig.SetNamespace(options.NewSpoke)
-		newConfig.SetAnnotations(oldConfig.GetAnnotations())
-		newConfig.SetFinalizers(oldConfig.GetFinalizers())
-		newConfig.SetOwnerReferences(oldConfig.GetOwnerReferences())
-		newConfig.SetLabels(oldConfig.GetLabels())
-
-		if err = options.Client.Create(ctx, newConfig); err != nil {
-			logger.Error(err, "failed creating new ConfigMap", "new-spoke", options.NewSpoke, "config-name", options.ConfigMapName)
+		return nil
+	}
+
+	if err := copyConfigMap(ctx, oldConfig, options); err != nil {
+		logger.Error(err, "failed migrating ConfigMap, keeping the old one", "new-spoke", options.NewSpoke, "config-name", options.ConfigMapName)
+		return err
+	}
+
+	if err := options.Client.Delete(ctx, oldConfig); err != nil {
+		logger.Error(err, "failed deleting ConfigMap", "old-spoke", options.OldSpoke, "config-name", options.ConfigMapName)
+		return err
+	}
+	return nil
+}
+
+// copyConfigMap is used for creating a copy of a ConfigMap in the NEW spoke namespace, or updating an existing one, and
+// verifying the copy content. Only the name, labels, annotations, and data are copied.
+func copyConfigMap(ctx context.Context, oldConfig *corev1.ConfigMap, options Options) error {
+	annotations := map[string]string{}
+	maps.Copy(annotations, oldConfig.GetAnnotations())
+	annotations[mcra.AnnotationCreatedBy] = mcra.AddonName
+	annotations[mcra.AnnotationFromAnnotation] = options.OldSpoke
+
+	newConfig := &corev1.ConfigMap{}
+	newConfig.SetName(oldConfig.Name)
+	newConfig.SetNamespace(options.NewSpoke)
+	newConfig.SetLabels(oldConfig.GetLabels())
+	newConfig.SetAnnotations(annotations)
+	// the maps are cloned, the server response is decoded into the copy
+	newConfig.Data = maps.Clone(oldConfig.Data)
+	newConfig.BinaryData = maps.Clone(oldConfig.BinaryData)
+
+	if err := options.Client.Create(ctx, newConfig); err != nil {
+		if !errors.IsAlreadyExists(err) {
+			return err
 		}
 
-		if err = options.Client.Delete(ctx, oldConfig); err != nil {
-			logger.Error(err, "failed deleting ConfigMap from", "old-spoke", options.OldSpoke, "config-name", options.ConfigMapName)
+		// the copy already exists in the NEW spoke, update it
+		existingConfig := &corev1.ConfigMap{}
+		if err = options.Reader.Get(ctx, client.ObjectKeyFromObject(newConfig), existingConfig); err != nil {
+			return err
+		}
+		newConfig.SetResourceVersion(existingConfig.GetResourceVersion())
+		if err = options.Client.Update(ctx, newConfig); err != nil {
+			return err
 		}
 	}
-}
 
-// init is registering migrateConfigMap for running.
-func init() {
-	actionFuncs = append(actionFuncs, migrateConfigMap)
+	// verify the copy returned by the server before the caller deletes the original
+	if !equality.Semantic.DeepEqual(newConfig.Data, oldConfig.Data) ||
+		!equality.Semantic.DeepEqual(newConfig.BinaryData, oldConfig.BinaryData) {
+		return fmt.Errorf("copied ConfigMap %s content differs from the original", newConfig.Name)
+	}
+	return nil
 }
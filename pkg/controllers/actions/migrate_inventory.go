@@ -0,0 +1,65 @@
+// Copyright (c) 2023 Red Hat, Inc.
+
+package actions
+
+// This file contains the action for preserving the OLD spoke's last known workload inventory for the NEW spoke.
+
+import (
+	"context"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/mcra"
+	"golang.org/x/exp/maps"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// migrateInventory is used for copying the last inventory reported by the OLD spoke's Agent into the NEW spoke
+// cluster-namespace, so the NEW spoke can be validated against it. The NEW spoke's Agent reports its own inventory in a
+// different ConfigMap.
+func migrateInventory(ctx context.Context, options Options) error {
+	logger := log.FromContext(ctx)
+	logger.Info("migrating inventory", "old-spoke", options.OldSpoke, "new-spoke", options.NewSpoke)
+
+	// the inventory ConfigMap resides in the cluster-namespace
+	oldInventorySubject := types.NamespacedName{
+		Namespace: options.OldSpoke,
+		Name:      mcra.InventoryConfigMapName,
+	}
+
+	oldInventory := &corev1.ConfigMap{}
+	if err := options.Client.Get(ctx, oldInventorySubject, oldInventory); err != nil {
+		logger.Info("no inventory found", "old-spoke", options.OldSpoke)
+		return nil
+	}
+
+	newInventory := &corev1.ConfigMap{}
+	newInventory.SetName(mcra.PreviousInventoryConfigMapName)
+	newInventory.SetNamespace(options.NewSpoke)
+	newInventory.SetAnnotations(map[string]string{
+		mcra.AnnotationCreatedBy:      mcra.AddonName,
+		mcra.AnnotationFromAnnotation: options.OldSpoke,
+	})
+	newInventory.Data = maps.Clone(oldInventory.Data)
+
+	// a re-run replacement finds the previous inventory already created, update it
+	if err := options.Client.Create(ctx, newInventory); err != nil {
+		if !errors.IsAlreadyExists(err) {
+			logger.Error(err, "failed creating previous inventory", "new-spoke", options.NewSpoke)
+			return err
+		}
+
+		existingInventory := &corev1.ConfigMap{}
+		if err = options.Reader.Get(ctx, client.ObjectKeyFromObject(newInventory), existingInventory); err != nil {
+			logger.Error(err, "failed fetching previous inventory", "new-spoke", options.NewSpoke)
+			return err
+		}
+		newInventory.SetResourceVersion(existingInventory.GetResourceVersion())
+		if err = options.Client.Update(ctx, newInventory); err != nil {
+			logger.Error(err, "failed updating previous inventory", "new-spoke", options.NewSpoke)
+			return err
+		}
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ package actions
 
 import (
 	"context"
+	"fmt"
 	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/mcra"
 	"golang.org/x/exp/maps"
 	"golang.org/x/exp/slices"
@@ -17,7 +18,7 @@ import (
 )
 
 // migrateManagedClusterAddon is used for moving the ManagedClusterAddon from the OLD spoke to the NEW one.
-func migrateManagedClusterAddon(ctx context.Context, options Options) {
+func migrateManagedClusterAddon(ctx context.Context, options Options) error {
 	logger := log.FromContext(ctx)
 	logger.Info("migrating ManagedClusterAddon resource", "old-spoke", options.OldSpoke, "new-spoke", options.NewSpoke)
 
@@ -30,66 +31,112 @@ func migrateManagedClusterAddon(ctx context.Context, options Options) {
 	// fetch ManagedClusterAddOn from OLD cluster, create a copy in the NEW cluster and delete the OLD one
 	oldMca := &addonv1alpha1.ManagedClusterAddOn{}
 	if err := options.Client.Get(ctx, oldMcaSubject, oldMca); err != nil {
+		if errors.IsNotFound(err) {
+			logger.Info("no ManagedClusterAddon found", "old-spoke", options.OldSpoke)
+			return nil
+		}
 		logger.Error(err, "failed fetching ManagedClusterAddon", "old-spoke", options.OldSpoke)
-		return
+		return err
 	}
 
+	return migrateMca(ctx, oldMca, options)
+}
+
+// migrateMca is used for moving a ManagedClusterAddon from the OLD spoke to the NEW one. If the ManagedClusterAddon
+// already exists in the NEW spoke, the OLD one will be merged into it. The OLD ManagedClusterAddon is deleted only after
+// its copy was verified in the NEW spoke. Returns the first error encountered.
+func migrateMca(ctx context.Context, oldMca *addonv1alpha1.ManagedClusterAddOn, options Options) error {
+	logger := log.FromContext(ctx)
+
 	// the ManagedClusterAddon resides in the cluster-namespace
 	newMcaSubject := types.NamespacedName{
 		Namespace: options.NewSpoke,
-		Name:      mcra.AddonName,
+		Name:      oldMca.Name,
 	}
 
 	// attempt to fetch ManagedClusterAddOn from NEW cluster, if exists - compare spec, if not create new
 	newMca := &addonv1alpha1.ManagedClusterAddOn{}
 	if err := options.Client.Get(ctx, newMcaSubject, newMca); err != nil {
 		if !errors.IsNotFound(err) {
-			logger.Error(err, "failed fetching ManagedClusterAddon", "new-spoke", options.NewSpoke)
-			return
+			logger.Error(err, "failed fetching ManagedClusterAddon", "new-spoke", options.NewSpoke, "addon-name", oldMca.Name)
+			return err
 		}
 
-		// create new ManagedClusterAddon for the NEW spoke and
-		if err = createNewMca(ctx, oldMca, options); err != nil {
-			logger.Error(err, "failed updating new ManagedClusterAddon", "new-spoke", options.NewSpoke)
+		// create new ManagedClusterAddon for the NEW spoke, keep the OLD one if failed
+		if newMca, err = createNewMca(ctx, oldMca, options); err != nil {
+			logger.Error(err, "failed creating new ManagedClusterAddon, keeping the old one", "new-spoke", options.NewSpoke, "addon-name", oldMca.Name)
+			return err
 		}
-
 	} else {
 		// compare new ManagedClusterAddon with previous one (new one created by addon install strategy)
 		if err = updateNewMca(ctx, newMca, oldMca, options); err != nil {
-			logger.Error(err, "failed creating new ManagedClusterAddon", "new-spoke", options.NewSpoke)
+			logger.Error(err, "failed updating new ManagedClusterAddon, keeping the old one", "new-spoke", options.NewSpoke, "addon-name", oldMca.Name)
+			return err
 		}
 	}
 
+	// verify the copy returned by the server before deleting the original
+	if err := verifyMcaCopy(newMca, oldMca, options); err != nil {
+		logger.Error(err, "failed verifying new ManagedClusterAddon, keeping the old one", "new-spoke", options.NewSpoke, "addon-name", oldMca.Name)
+		return err
+	}
+
 	// delete the OLD one Spoke
 	if err := options.Client.Delete(ctx, oldMca); err != nil {
-		logger.Error(err, "failed deleting ManagedClusterAddon", "old-spoke", options.OldSpoke)
+		logger.Error(err, "failed deleting ManagedClusterAddon", "old-spoke", options.OldSpoke, "addon-name", oldMca.Name)
+		return err
+	}
+	return nil
+}
+
+// verifyMcaCopy is used for verifying the NEW ManagedClusterAddon carries the OLD one's labels, and config references.
+func verifyMcaCopy(newMca, oldMca *addonv1alpha1.ManagedClusterAddOn, options Options) error {
+	for key, value := range oldMca.GetLabels() {
+		if newMca.GetLabels()[key] != value {
+			return fmt.Errorf("copied ManagedClusterAddon %s is missing label %s", newMca.Name, key)
+		}
+	}
+	for _, config := range migrateMcaConfigs(nil, oldMca.Spec.Configs, options) {
+		if !slices.Contains(newMca.Spec.Configs, config) {
+			return fmt.Errorf("copied ManagedClusterAddon %s is missing config %s/%s", newMca.Name, config.Namespace, config.Name)
+		}
 	}
+	return nil
 }
 
-// createNewMca is used for creating a new ManagedClusterAddon from a previous one
-func createNewMca(ctx context.Context, oldMca *addonv1alpha1.ManagedClusterAddOn, options Options) error {
+// createNewMca is used for creating a new ManagedClusterAddon from a previous one. Returns the object created by the
+// server.
+func createNewMca(ctx context.Context, oldMca *addonv1alpha1.ManagedClusterAddOn, options Options) (*addonv1alpha1.ManagedClusterAddOn, error) {
 	newMca := oldMca.DeepCopy()
 	newMca.ObjectMeta = metav1.ObjectMeta{}
+	newMca.Status = addonv1alpha1.ManagedClusterAddOnStatus{}
 
-	newMca.SetName(mcra.AddonName)
+	newMca.SetName(oldMca.Name)
 	newMca.SetNamespace(options.NewSpoke)
 	newMca.SetLabels(oldMca.GetLabels())
 
 	newMca.SetOwnerReferences(oldMca.GetOwnerReferences())
 	newMca.SetFinalizers(oldMca.GetFinalizers())
 
-	annotations := oldMca.GetAnnotations()
+	annotations := map[string]string{}
+	maps.Copy(annotations, oldMca.GetAnnotations())
 	annotations[mcra.AnnotationCreatedBy] = mcra.AddonName
 	annotations[mcra.AnnotationFromAnnotation] = options.OldSpoke
 	newMca.SetAnnotations(annotations)
 
-	return options.Client.Create(ctx, newMca)
+	newMca.Spec.Configs = migrateMcaConfigs(nil, oldMca.Spec.Configs, options)
+
+	if err := options.Client.Create(ctx, newMca); err != nil {
+		return nil, err
+	}
+	return newMca, nil
 }
 
 // updateNewMca is used for updating a pre-existing ManagedClusterAddon from a previous one. Presumably the new once was
 // created by the addon when configured to install-all-strategy.
 func updateNewMca(ctx context.Context, newMca, oldMca *addonv1alpha1.ManagedClusterAddOn, options Options) error {
-	labels := newMca.GetLabels()
+	labels := map[string]string{}
+	maps.Copy(labels, newMca.GetLabels())
 	maps.Copy(labels, oldMca.GetLabels())
 	newMca.SetLabels(labels)
 
@@ -107,17 +154,32 @@ func updateNewMca(ctx context.Context, newMca, oldMca *addonv1alpha1.ManagedClus
 			finalizers = append(finalizers, oldFinalizer)
 		}
 	}
+	newMca.SetFinalizers(finalizers)
 
-	annotations := newMca.GetAnnotations()
+	annotations := map[string]string{}
+	maps.Copy(annotations, newMca.GetAnnotations())
 	maps.Copy(annotations, oldMca.GetAnnotations())
 	annotations[mcra.AnnotationCreatedBy] = mcra.AddonName
 	annotations[mcra.AnnotationFromAnnotation] = options.OldSpoke
 	newMca.SetAnnotations(annotations)
 
+	newMca.Spec.Configs = migrateMcaConfigs(newMca.Spec.Configs, oldMca.Spec.Configs, options)
+
 	return options.Client.Update(ctx, newMca)
 }
 
-// init is registering migrateManagedClusterAddon for running.
-func init() {
-	actionFuncs = append(actionFuncs, migrateManagedClusterAddon)
+// migrateMcaConfigs is used for adding the OLD ManagedClusterAddon's config references to the NEW one's. References to
+// configurations residing in the OLD spoke namespace are modified to point to the NEW spoke namespace, where they are
+// moved to by the other actions.
+func migrateMcaConfigs(newConfigs, oldConfigs []addonv1alpha1.AddOnConfig, options Options) []addonv1alpha1.AddOnConfig {
+	for _, oldConfig := range oldConfigs {
+		config := oldConfig
+		if config.Namespace == options.OldSpoke {
+			config.Namespace = options.NewSpoke
+		}
+		if !slices.Contains(newConfigs, config) {
+			newConfigs = append(newConfigs, config)
+		}
+	}
+	return newConfigs
 }
@@ -0,0 +1,61 @@
+// Copyright (c) 2023 Red Hat, Inc.
+
+package actions
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	addonv1alpha1 "open-cluster-management.io/api/addon/v1alpha1"
+	"testing"
+)
+
+func TestVerifyMcaCopy(t *testing.T) {
+	options := Options{OldSpoke: "spoke1", NewSpoke: "spoke2"}
+	config := func(namespace string) addonv1alpha1.AddOnConfig {
+		return addonv1alpha1.AddOnConfig{
+			ConfigGroupResource: addonv1alpha1.ConfigGroupResource{Group: "addon.open-cluster-management.io", Resource: "addondeploymentconfigs"},
+			ConfigReferent:      addonv1alpha1.ConfigReferent{Namespace: namespace, Name: "deploy-config"},
+		}
+	}
+	oldMca := &addonv1alpha1.ManagedClusterAddOn{
+		ObjectMeta: metav1.ObjectMeta{Name: "addon", Labels: map[string]string{"tier": "gold"}},
+		Spec:       addonv1alpha1.ManagedClusterAddOnSpec{Configs: []addonv1alpha1.AddOnConfig{config("spoke1")}},
+	}
+
+	tests := []struct {
+		name    string
+		newMca  *addonv1alpha1.ManagedClusterAddOn
+		wantErr bool
+	}{
+		{
+			name: "verified copy",
+			newMca: &addonv1alpha1.ManagedClusterAddOn{
+				ObjectMeta: metav1.ObjectMeta{Name: "addon", Labels: map[string]string{"tier": "gold", "env": "prod"}},
+				Spec:       addonv1alpha1.ManagedClusterAddOnSpec{Configs: []addonv1alpha1.AddOnConfig{config("spoke2")}},
+			},
+		},
+		{
+			name: "missing label",
+			newMca: &addonv1alpha1.ManagedClusterAddOn{
+				ObjectMeta: metav1.ObjectMeta{Name: "addon"},
+				Spec:       addonv1alpha1.ManagedClusterAddOnSpec{Configs: []addonv1alpha1.AddOnConfig{config("spoke2")}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "config not moved to the new spoke",
+			newMca: &addonv1alpha1.ManagedClusterAddOn{
+				ObjectMeta: metav1.ObjectMeta{Name: "addon", Labels: map[string]string{"tier": "gold"}},
+				Spec:       addonv1alpha1.ManagedClusterAddOnSpec{Configs: []addonv1alpha1.AddOnConfig{config("spoke1")}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := verifyMcaCopy(tt.newMca, oldMca, options); (err != nil) != tt.wantErr {
+				t.Errorf("verifyMcaCopy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2023 Red Hat, Inc.
+
+package actions
+
+// This file contains the action for moving all other ManagedClusterAddons between spokes.
+
+import (
+	"context"
+	"fmt"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/mcra"
+	"golang.org/x/exp/slices"
+	addonv1alpha1 "open-cluster-management.io/api/addon/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// migrateManagedClusterAddons is used for moving all the ManagedClusterAddons found in the OLD spoke namespace, i.e.
+// policy, search, observability, etc., to the NEW one. The Addon's own ManagedClusterAddon is handled by
+// migrateManagedClusterAddon. If the AddonsAllowList option is set, only listed addons are moved, addons listed in the
+// AddonsDenyList option are never moved.
+func migrateManagedClusterAddons(ctx context.Context, options Options) error {
+	logger := log.FromContext(ctx)
+	logger.Info("migrating all ManagedClusterAddon resources", "old-spoke", options.OldSpoke, "new-spoke", options.NewSpoke)
+
+	oldMcas := &addonv1alpha1.ManagedClusterAddOnList{}
+	if err := options.Client.List(ctx, oldMcas, &client.ListOptions{Namespace: options.OldSpoke}); err != nil {
+		logger.Error(err, "failed listing ManagedClusterAddons", "old-spoke", options.OldSpoke)
+		return err
+	}
+
+	failed := 0
+	for _, oldMca := range oldMcas.Items {
+		if !shouldMigrateAddon(oldMca.Name, options) {
+			logger.Info("skipping ManagedClusterAddon", "old-spoke", options.OldSpoke, "addon-name", oldMca.Name)
+			continue
+		}
+
+		if err := migrateMca(ctx, &oldMca, options); err != nil {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed migrating %d ManagedClusterAddons", failed)
+	}
+	return nil
+}
+
+// shouldMigrateAddon is used for deciding if an addon should be moved based on the allow and deny lists.
+func shouldMigrateAddon(addonName string, options Options) bool {
+	// the addon's own ManagedClusterAddon is handled by migrateManagedClusterAddon
+	if addonName == mcra.AddonName {
+		return false
+	}
+	if slices.Contains(options.AddonsDenyList, addonName) {
+		return false
+	}
+	return len(options.AddonsAllowList) == 0 || slices.Contains(options.AddonsAllowList, addonName)
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2023 Red Hat, Inc.
+
+package actions
+
+// This file contains the action for copying ManifestWorks between spokes.
+
+import (
+	"context"
+	"fmt"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/mcra"
+	"golang.org/x/exp/maps"
+	"k8s.io/apimachinery/pkg/api/errors"
+	addonv1alpha1 "open-cluster-management.io/api/addon/v1alpha1"
+	workv1 "open-cluster-management.io/api/work/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// migrateManifestWorks is used for copying all the ManifestWorks found in the OLD spoke namespace to the NEW one. The
+// copies are created without the status and the server generated metadata. ManifestWorks created by the addon framework
+// for deploying addon agents are skipped, these are created by the addon managers for the NEW spoke, as are
+// ManifestWorks restoring workloads and smoke checking. The OLD ManifestWorks are left for the cluster-namespace cleanup. The copied ManifestWorks are recorded in the Replacement,
+// their applied status is validated by the ValidationReconciler.
+func migrateManifestWorks(ctx context.Context, options Options) error {
+	logger := log.FromContext(ctx)
+	logger.Info("migrating ManifestWork resources", "old-spoke", options.OldSpoke, "new-spoke", options.NewSpoke)
+
+	oldWorks := &workv1.ManifestWorkList{}
+	if err := options.Client.List(ctx, oldWorks, &client.ListOptions{Namespace: options.OldSpoke}); err != nil {
+		logger.Error(err, "failed listing ManifestWorks", "old-spoke", options.OldSpoke)
+		return err
+	}
+
+	var copiedWorks []string
+	failed := 0
+	for _, oldWork := range oldWorks.Items {
+		// agent deployments are created by the addon managers
+		if _, isAddon := oldWork.GetLabels()[addonv1alpha1.AddonLabelKey]; isAddon {
+			logger.Info("skipping addon ManifestWork", "old-spoke", options.OldSpoke, "work-name", oldWork.Name)
+			continue
+		}
+
+		// the OLD spoke's own workload restore and smoke checks are not repeated, the NEW spoke has its own
+		if isRecoveryWork(&oldWork) {
+			logger.Info("skipping recovery ManifestWork", "old-spoke", options.OldSpoke, "work-name", oldWork.Name)
+			continue
+		}
+
+		if err := copyManifestWork(ctx, &oldWork, options); err != nil {
+			logger.Error(err, "failed copying ManifestWork", "new-spoke", options.NewSpoke, "work-name", oldWork.Name)
+			failed++
+			continue
+		}
+		copiedWorks = append(copiedWorks, oldWork.Name)
+	}
+
+	if options.Replacement != nil {
+		options.Replacement.ManifestWorks = copiedWorks
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed copying %d ManifestWorks", failed)
+	}
+	return nil
+}
+
+// copyManifestWork is used for creating a copy of a ManifestWork in the NEW spoke namespace, only the name, labels,
+// annotations, and spec are copied. If the ManifestWork already exists in the NEW spoke, its spec will be updated.
+func copyManifestWork(ctx context.Context, oldWork *workv1.ManifestWork, options Options) error {
+	annotations := map[string]string{}
+	maps.Copy(annotations, oldWork.GetAnnotations())
+	annotations[mcra.AnnotationCreatedBy] = mcra.AddonName
+	annotations[mcra.AnnotationFromAnnotation] = options.OldSpoke
+
+	newWork := &workv1.ManifestWork{}
+	newWork.SetName(oldWork.Name)
+	newWork.SetNamespace(options.NewSpoke)
+	newWork.SetLabels(oldWork.GetLabels())
+	newWork.SetAnnotations(annotations)
+	newWork.Spec = *oldWork.Spec.DeepCopy()
+
+	err := options.Client.Create(ctx, newWork)
+	if !errors.IsAlreadyExists(err) {
+		return err
+	}
+
+	// the ManifestWork already exists in the NEW spoke, update it
+	existingWork := &workv1.ManifestWork{}
+	if err = options.Client.Get(ctx, client.ObjectKeyFromObject(newWork), existingWork); err != nil {
+		return err
+	}
+	existingWork.SetAnnotations(annotations)
+	existingWork.Spec = newWork.Spec
+	return options.Client.Update(ctx, existingWork)
+}
+
+// isRecoveryWork returns true if the ManifestWork was created for restoring the workload of a previous spoke, or for
+// smoke checking a replacement spoke.
+func isRecoveryWork(work *workv1.ManifestWork) bool {
+	_, isRestore := work.GetLabels()[mcra.LabelRestoreFrom]
+	_, isSmokeCheck := work.GetLabels()[mcra.LabelSmokeCheck]
+	return isRestore || isSmokeCheck
+}
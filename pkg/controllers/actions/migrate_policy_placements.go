@@ -0,0 +1,88 @@
+// Copyright (c) 2023 Red Hat, Inc.
+
+package actions
+
+// This file contains the action for moving governance policies targeting the OLD spoke by name to the NEW spoke.
+
+import (
+	"context"
+	"fmt"
+	"golang.org/x/exp/maps"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sort"
+)
+
+var placementBindingListGVK = schema.GroupVersionKind{Group: "policy.open-cluster-management.io", Version: "v1", Kind: "PlacementBindingList"}
+
+// migratePolicyPlacements is used for moving governance policies targeting the OLD spoke by name to the NEW spoke. The
+// PlacementBindings are used for finding the PlacementRules and Placements used by the root policies. PlacementRules
+// listing the OLD spoke in their clusters list or in their clusterSelector by name, and Placements selecting the OLD
+// spoke by name in their predicates, are rewritten to target the NEW spoke. The policies will then be propagated to the
+// NEW spoke by the policy propagator. The policies bound to the modified placements are recorded in the Replacement.
+// Nothing is migrated when the governance policy framework is not installed.
+func migratePolicyPlacements(ctx context.Context, options Options) error {
+	logger := log.FromContext(ctx)
+	logger.Info("migrating policy placements", "old-spoke", options.OldSpoke, "new-spoke", options.NewSpoke)
+
+	bindings := &unstructured.UnstructuredList{}
+	bindings.SetGroupVersionKind(placementBindingListGVK)
+	if err := options.Client.List(ctx, bindings); err != nil {
+		if meta.IsNoMatchError(err) {
+			logger.Info("governance policies not installed, skipping")
+			return nil
+		}
+		logger.Error(err, "failed listing PlacementBindings")
+		return err
+	}
+
+	failed := 0
+	migrated := map[string]bool{}
+	// placements are shared between bindings, we only need to rewrite each placement once
+	rewritten := map[types.NamespacedName]bool{}
+	for _, binding := range bindings.Items {
+		refKind, _, _ := unstructured.NestedString(binding.Object, "placementRef", "kind")
+		refName, _, _ := unstructured.NestedString(binding.Object, "placementRef", "name")
+		refSubject := types.NamespacedName{Namespace: binding.GetNamespace(), Name: refName}
+
+		modified, found := rewritten[refSubject]
+		if !found {
+			var err error
+			if modified, err = rewritePlacementRef(ctx, refSubject, refKind, options); err != nil {
+				logger.Error(err, "failed rewriting placement", "kind", refKind, "placement", refSubject.String())
+				failed++
+				continue
+			}
+			rewritten[refSubject] = modified
+		}
+
+		if modified {
+			subjects, _, _ := unstructured.NestedSlice(binding.Object, "subjects")
+			for _, subject := range subjects {
+				subjectMap, _ := subject.(map[string]interface{})
+				subjectKind, _ := subjectMap["kind"].(string)
+				subjectName, _ := subjectMap["name"].(string)
+				migrated[types.NamespacedName{Namespace: binding.GetNamespace(), Name: subjectName}.String()] = true
+				logger.Info("policy migrated to new spoke",
+					"old-spoke", options.OldSpoke,
+					"new-spoke", options.NewSpoke,
+					"kind", subjectKind,
+					"policy", types.NamespacedName{Namespace: binding.GetNamespace(), Name: subjectName}.String(),
+					"placement", refSubject.String())
+			}
+		}
+	}
+
+	if options.Replacement != nil && len(migrated) > 0 {
+		options.Replacement.Policies = maps.Keys(migrated)
+		sort.Strings(options.Replacement.Policies)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed rewriting %d policy placements", failed)
+	}
+	return nil
+}
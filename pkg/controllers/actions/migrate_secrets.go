@@ -0,0 +1,134 @@
+// Copyright (c) 2023 Red Hat, Inc.
+
+package actions
+
+// This file contains the action for copying selected secrets from the OLD spoke namespace to the NEW one.
+
+import (
+	"context"
+	"fmt"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/mcra"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"strings"
+)
+
+// labelHiveSecretType is set by Hive on the secrets it generates for a ClusterDeployment.
+const labelHiveSecretType = "hive.openshift.io/secret-type"
+
+// hiveSecretSuffixes are the name suffixes of the admin credentials secrets generated by Hive for a ClusterDeployment.
+var hiveSecretSuffixes = []string{"-admin-kubeconfig", "-admin-password"}
+
+// migrateSecrets is used for copying secrets selected by the SecretsSelector label selector and the SecretsNames list
+// options from the OLD spoke namespace to the NEW one. Nothing is copied if neither option is set. Secrets owned by
+// Hive, i.e. the OLD spoke's admin kubeconfig and password, and service account tokens, are never copied. Existing
+// copies are updated, the original secrets are kept. The names of the copied secrets are recorded in the Replacement.
+// Secrets are read directly from the API server, not cached by the manager.
+func migrateSecrets(ctx context.Context, options Options) error {
+	logger := log.FromContext(ctx)
+
+	if options.SecretsSelector == "" && len(options.SecretsNames) == 0 {
+		logger.Info("no secrets selected for migration", "old-spoke", options.OldSpoke)
+		return nil
+	}
+	logger.Info("migrating secrets", "old-spoke", options.OldSpoke, "new-spoke", options.NewSpoke)
+
+	oldSecrets := &corev1.SecretList{}
+	if err := options.Reader.List(ctx, oldSecrets, client.InNamespace(options.OldSpoke)); err != nil {
+		logger.Error(err, "failed listing secrets", "old-spoke", options.OldSpoke)
+		return err
+	}
+
+	selector := labels.Nothing()
+	if options.SecretsSelector != "" {
+		var err error
+		if selector, err = labels.Parse(options.SecretsSelector); err != nil {
+			logger.Error(err, "failed parsing secrets selector", "selector", options.SecretsSelector)
+			return err
+		}
+	}
+
+	failed := 0
+	for _, oldSecret := range oldSecrets.Items {
+		if !selector.Matches(labels.Set(oldSecret.Labels)) && !slices.Contains(options.SecretsNames, oldSecret.Name) {
+			continue
+		}
+
+		if reason := secretRefusal(&oldSecret); reason != "" {
+			logger.Info(fmt.Sprintf("refusing to copy secret, %s", reason), "old-spoke", options.OldSpoke, "secret", oldSecret.Name)
+			continue
+		}
+
+		if err := copySecret(ctx, &oldSecret, options); err != nil {
+			logger.Error(err, "failed copying secret", "new-spoke", options.NewSpoke, "secret", oldSecret.Name)
+			failed++
+			continue
+		}
+		logger.Info("copied secret", "new-spoke", options.NewSpoke, "secret", oldSecret.Name)
+
+		if options.Replacement != nil {
+			options.Replacement.CopiedSecrets = append(options.Replacement.CopiedSecrets, oldSecret.Name)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed copying %d secrets", failed)
+	}
+	return nil
+}
+
+// secretRefusal returns the reason for refusing to copy a secret, or an empty string if the secret can be copied.
+func secretRefusal(secret *corev1.Secret) string {
+	for _, owner := range secret.OwnerReferences {
+		if owner.Kind == "ClusterDeployment" {
+			return "owned by a ClusterDeployment"
+		}
+	}
+	if _, found := secret.Labels[labelHiveSecretType]; found {
+		return "generated by hive"
+	}
+	for _, suffix := range hiveSecretSuffixes {
+		if strings.HasSuffix(secret.Name, suffix) {
+			return "hive admin credentials"
+		}
+	}
+	if secret.Type == corev1.SecretTypeServiceAccountToken {
+		return "service account token"
+	}
+	return ""
+}
+
+// copySecret is used for creating a copy of a secret in the NEW spoke namespace. Only the name, labels, annotations,
+// type, and data are copied. If the copy already exists, it will be updated.
+func copySecret(ctx context.Context, oldSecret *corev1.Secret, options Options) error {
+	annotations := map[string]string{}
+	maps.Copy(annotations, oldSecret.Annotations)
+	annotations[mcra.AnnotationCreatedBy] = mcra.AddonName
+	annotations[mcra.AnnotationFromAnnotation] = options.OldSpoke
+
+	newSecret := &corev1.Secret{}
+	newSecret.SetName(oldSecret.Name)
+	newSecret.SetNamespace(options.NewSpoke)
+	newSecret.SetLabels(oldSecret.Labels)
+	newSecret.SetAnnotations(annotations)
+	newSecret.Type = oldSecret.Type
+	newSecret.Data = oldSecret.Data
+
+	err := options.Client.Create(ctx, newSecret)
+	if !errors.IsAlreadyExists(err) {
+		return err
+	}
+
+	// the copy already exists in the NEW spoke, update it
+	existingSecret := &corev1.Secret{}
+	if err = options.Reader.Get(ctx, client.ObjectKeyFromObject(newSecret), existingSecret); err != nil {
+		return err
+	}
+	newSecret.SetResourceVersion(existingSecret.GetResourceVersion())
+	return options.Client.Update(ctx, newSecret)
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2023 Red Hat, Inc.
+
+package actions
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+)
+
+func TestSecretRefusal(t *testing.T) {
+	tests := []struct {
+		name   string
+		secret corev1.Secret
+		want   string
+	}{
+		{
+			name:   "plain secret is copied",
+			secret: corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "app-credentials"}, Type: corev1.SecretTypeOpaque},
+			want:   "",
+		},
+		{
+			name: "owned by a cluster deployment",
+			secret: corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+				Name:            "app-credentials",
+				OwnerReferences: []metav1.OwnerReference{{Kind: "ClusterDeployment", Name: "spoke1"}},
+			}},
+			want: "owned by a ClusterDeployment",
+		},
+		{
+			name: "labeled by hive",
+			secret: corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+				Name:   "spoke1-bootstrap",
+				Labels: map[string]string{labelHiveSecretType: "kubeconfig"},
+			}},
+			want: "generated by hive",
+		},
+		{
+			name:   "admin kubeconfig",
+			secret: corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "spoke1-0-abcde-admin-kubeconfig"}},
+			want:   "hive admin credentials",
+		},
+		{
+			name:   "admin password",
+			secret: corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "spoke1-0-abcde-admin-password"}},
+			want:   "hive admin credentials",
+		},
+		{
+			name:   "service account token",
+			secret: corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "builder-token"}, Type: corev1.SecretTypeServiceAccountToken},
+			want:   "service account token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := secretRefusal(&tt.secret); got != tt.want {
+				t.Errorf("secretRefusal() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
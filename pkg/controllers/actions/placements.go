@@ -0,0 +1,142 @@
+// Copyright (c) 2023 Red Hat, Inc.
+
+package actions
+
+// This file contains utility functions for rewriting placements targeting the OLD spoke by name, for use by actions.
+
+import (
+	"context"
+	"fmt"
+	"golang.org/x/exp/slices"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	clusterv1beta1 "open-cluster-management.io/api/cluster/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// clusterNameLabel is the label set by ACM on every ManagedCluster with the cluster's name.
+const clusterNameLabel = "name"
+
+var placementRuleGVK = schema.GroupVersionKind{Group: "apps.open-cluster-management.io", Version: "v1", Kind: "PlacementRule"}
+
+// rewritePlacementRef is used for rewriting a Placement or a PlacementRule, referenced by kind and name, targeting the
+// OLD spoke by name, to target the NEW spoke. Returns true if the referenced placement was modified.
+func rewritePlacementRef(ctx context.Context, subject types.NamespacedName, kind string, options Options) (bool, error) {
+	switch kind {
+	case "PlacementRule":
+		return rewritePlacementRule(ctx, subject, options)
+	case "Placement":
+		return rewritePlacement(ctx, subject, options)
+	default:
+		return false, fmt.Errorf("unknown placement kind %s", kind)
+	}
+}
+
+// rewritePlacementRule is used for rewriting a PlacementRule targeting the OLD spoke by name, to target the NEW spoke.
+// Returns true if the PlacementRule was modified.
+func rewritePlacementRule(ctx context.Context, subject types.NamespacedName, options Options) (bool, error) {
+	rule := &unstructured.Unstructured{}
+	rule.SetGroupVersionKind(placementRuleGVK)
+	if err := options.Client.Get(ctx, subject, rule); err != nil {
+		return false, err
+	}
+	rulePatch := client.MergeFrom(rule.DeepCopy())
+
+	modified, err := rewriteClusterTargets(rule, options, "spec")
+	if err != nil || !modified {
+		return false, err
+	}
+	return true, options.Client.Patch(ctx, rule, rulePatch)
+}
+
+// rewritePlacement is used for rewriting a Placement selecting the OLD spoke by name in its predicates, to target the
+// NEW spoke. Returns true if the Placement was modified.
+func rewritePlacement(ctx context.Context, subject types.NamespacedName, options Options) (bool, error) {
+	placement := &clusterv1beta1.Placement{}
+	if err := options.Client.Get(ctx, subject, placement); err != nil {
+		return false, err
+	}
+	placementPatch := client.MergeFrom(placement.DeepCopy())
+
+	modified := false
+	for i := range placement.Spec.Predicates {
+		if replaceNameInSelector(&placement.Spec.Predicates[i].RequiredClusterSelector.LabelSelector, options) {
+			modified = true
+		}
+	}
+
+	if !modified {
+		return false, nil
+	}
+	return true, options.Client.Patch(ctx, placement, placementPatch)
+}
+
+// rewriteClusterTargets is used for rewriting the 'clusters' list and the 'clusterSelector' label selector found in the
+// given path of an unstructured object, i.e. a PlacementRule's spec or a Subscription's spec.placement. The OLD spoke
+// name is replaced with the NEW one. The object is modified in place, returns true if it was modified.
+func rewriteClusterTargets(obj *unstructured.Unstructured, options Options, path ...string) (bool, error) {
+	modified := false
+
+	// clusters is a list of objects with a name
+	clusters, _, _ := unstructured.NestedSlice(obj.Object, append(path, "clusters")...)
+	for i, cluster := range clusters {
+		clusterMap, ok := cluster.(map[string]interface{})
+		if ok && clusterMap["name"] == options.OldSpoke {
+			clusterMap["name"] = options.NewSpoke
+			clusters[i] = clusterMap
+			modified = true
+		}
+	}
+	if modified {
+		if err := unstructured.SetNestedSlice(obj.Object, clusters, append(path, "clusters")...); err != nil {
+			return false, err
+		}
+	}
+
+	// clusterSelector is a label selector
+	if selectorMap, found, _ := unstructured.NestedMap(obj.Object, append(path, "clusterSelector")...); found {
+		selector := &metav1.LabelSelector{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(selectorMap, selector); err != nil {
+			return false, err
+		}
+		if replaceNameInSelector(selector, options) {
+			selectorMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(selector)
+			if err != nil {
+				return false, err
+			}
+			if err = unstructured.SetNestedMap(obj.Object, selectorMap, append(path, "clusterSelector")...); err != nil {
+				return false, err
+			}
+			modified = true
+		}
+	}
+
+	return modified, nil
+}
+
+// replaceNameInSelector is used for replacing the OLD spoke name with the NEW one in a label selector using the cluster
+// name label. Returns true if the selector was modified.
+func replaceNameInSelector(selector *metav1.LabelSelector, options Options) bool {
+	modified := false
+
+	if selector.MatchLabels[clusterNameLabel] == options.OldSpoke {
+		selector.MatchLabels[clusterNameLabel] = options.NewSpoke
+		modified = true
+	}
+
+	for i, expression := range selector.MatchExpressions {
+		// only inclusive expressions are rewritten, excluding the NEW spoke is not our call
+		if expression.Key != clusterNameLabel || expression.Operator != metav1.LabelSelectorOpIn {
+			continue
+		}
+		if idx := slices.Index(expression.Values, options.OldSpoke); idx >= 0 {
+			selector.MatchExpressions[i].Values[idx] = options.NewSpoke
+			modified = true
+		}
+	}
+
+	return modified
+}
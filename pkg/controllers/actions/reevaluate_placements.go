@@ -0,0 +1,107 @@
+// Copyright (c) 2023 Red Hat, Inc.
+
+package actions
+
+// This file contains the actions for recording the Placements that selected the OLD spoke, and forcing their
+// re-evaluation.
+
+import (
+	"context"
+	"fmt"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/mcra"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	clusterv1beta1 "open-cluster-management.io/api/cluster/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sort"
+	"strings"
+	"time"
+)
+
+// recordPlacements is used for recording the Placements with decisions including the OLD spoke in the Replacement, for
+// reevaluatePlacements. Note, this action is required to run before the OLD ManagedCluster is deleted by
+// compareManagedClusterAndDeleteOld, as its decisions are removed with it.
+func recordPlacements(ctx context.Context, options Options) error {
+	logger := log.FromContext(ctx)
+	logger.Info("recording Placements for rescheduling", "old-spoke", options.OldSpoke, "new-spoke", options.NewSpoke)
+
+	decisions := &clusterv1beta1.PlacementDecisionList{}
+	if err := options.Client.List(ctx, decisions); err != nil {
+		logger.Error(err, "failed listing PlacementDecisions")
+		return err
+	}
+
+	// collect the Placements with decisions for the OLD spoke
+	placements := map[types.NamespacedName]bool{}
+	for _, decision := range decisions.Items {
+		placementName, found := decision.GetLabels()[clusterv1beta1.PlacementLabel]
+		if !found {
+			continue
+		}
+		for _, clusterDecision := range decision.Status.Decisions {
+			if clusterDecision.ClusterName == options.OldSpoke {
+				placements[types.NamespacedName{Namespace: decision.Namespace, Name: placementName}] = true
+			}
+		}
+	}
+
+	var rescheduled []string
+	for placementSubject := range placements {
+		rescheduled = append(rescheduled, placementSubject.String())
+	}
+	sort.Strings(rescheduled)
+	logger.Info("Placements to be rescheduled", "old-spoke", options.OldSpoke, "placements", rescheduled)
+
+	if options.Replacement != nil {
+		options.Replacement.Placements = rescheduled
+	}
+	return nil
+}
+
+// reevaluatePlacements is used for forcing re-evaluation of the Placements recorded by recordPlacements, so their
+// workloads are rescheduled. The re-evaluation is triggered by annotating the Placement, the placement controller
+// reschedules on every Placement change. Note, this action is required to run after the OLD ManagedCluster was deleted,
+// by then the NEW one was assigned to the ManagedClusterSets and labeled.
+func reevaluatePlacements(ctx context.Context, options Options) error {
+	logger := log.FromContext(ctx)
+	if options.Replacement == nil || len(options.Replacement.Placements) == 0 {
+		logger.Info("no Placements to re-evaluate", "old-spoke", options.OldSpoke)
+		return nil
+	}
+	logger.Info("re-evaluating Placements", "old-spoke", options.OldSpoke, "new-spoke", options.NewSpoke)
+
+	failed := 0
+	for _, placementName := range options.Replacement.Placements {
+		namespace, name, _ := strings.Cut(placementName, string(types.Separator))
+		placement := &clusterv1beta1.Placement{}
+		if err := options.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, placement); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			logger.Error(err, "failed fetching Placement", "placement", placementName)
+			failed++
+			continue
+		}
+
+		placementPatch := client.MergeFrom(placement.DeepCopy())
+		annotations := placement.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[mcra.AnnotationReevaluatedAt] = time.Now().UTC().Format(time.RFC3339)
+		placement.SetAnnotations(annotations)
+
+		if err := options.Client.Patch(ctx, placement, placementPatch); err != nil {
+			logger.Error(err, "failed re-evaluating Placement", "placement", placementName)
+			failed++
+			continue
+		}
+		logger.Info("Placement re-evaluated", "placement", placementName)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed re-evaluating %d placements", failed)
+	}
+	return nil
+}
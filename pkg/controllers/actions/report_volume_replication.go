@@ -0,0 +1,50 @@
+// Copyright (c) 2023 Red Hat, Inc.
+
+package actions
+
+// This file contains the action for reporting the replication of the OLD spoke's volumes.
+
+import (
+	"context"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/inventory"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/mcra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sort"
+)
+
+// reportVolumeReplication is used for recording the OLD spoke's replicated and unreplicated PersistentVolumeClaims in
+// the Replacement, based on the last inventory reported by the OLD spoke's Agent. A freshly claimed cluster holds no
+// replicas, the replicated claims are recovered on the NEW spoke by their replication tooling, the data of the
+// unreplicated ones is lost.
+func reportVolumeReplication(ctx context.Context, options Options) error {
+	logger := log.FromContext(ctx)
+	logger.Info("reporting volume replication", "old-spoke", options.OldSpoke)
+
+	// the inventory ConfigMap resides in the cluster-namespace
+	inventoryMap := &corev1.ConfigMap{}
+	if err := options.Client.Get(ctx, types.NamespacedName{Namespace: options.OldSpoke, Name: mcra.InventoryConfigMapName}, inventoryMap); err != nil {
+		logger.Info("no inventory found", "old-spoke", options.OldSpoke)
+		return nil
+	}
+
+	inv, err := inventory.FromConfigMap(inventoryMap)
+	if err != nil {
+		logger.Error(err, "failed parsing inventory", "old-spoke", options.OldSpoke)
+		return err
+	}
+
+	replicated := inv.ReplicatedClaims()
+	unreplicated := inv.UnreplicatedClaims()
+	sort.Strings(replicated)
+	sort.Strings(unreplicated)
+	if options.Replacement != nil {
+		options.Replacement.ReplicatedVolumes = replicated
+		options.Replacement.UnreplicatedVolumes = unreplicated
+	}
+	if len(unreplicated) > 0 {
+		logger.Info("WARNING data of unreplicated volumes is lost", "old-spoke", options.OldSpoke, "claims", unreplicated)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2023 Red Hat, Inc.
+
+package actions
+
+// This file contains the action for running smoke checks on the NEW spoke.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/mcra"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	workv1 "open-cluster-management.io/api/work/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// SmokeCheckSucceededFeedback is the name of the ManifestWork status feedback reporting the succeeded pods of a smoke
+// check Job.
+const SmokeCheckSucceededFeedback = "succeeded"
+
+// SmokeCheck describes manifests applied to the NEW spoke for verifying it works. The check succeeds when its
+// ManifestWork is applied and all of its Jobs succeed.
+type SmokeCheck struct {
+	Name      string            `json:"name"`
+	Manifests []workv1.Manifest `json:"manifests"`
+}
+
+// runSmokeChecks is used for creating a ManifestWork per smoke check in the NEW spoke. Job manifests are configured
+// to report their succeeded pods back, tracked by the ValidationReconciler. Runs after all the migrations and the
+// restore, so the checks run against the restored workload.
+func runSmokeChecks(ctx context.Context, options Options) error {
+	logger := log.FromContext(ctx)
+
+	var failures []string
+	for _, check := range options.SmokeChecks {
+		work, err := smokeCheckWork(check, options.NewSpoke)
+		if err != nil {
+			logger.Error(err, "invalid smoke check", "new-spoke", options.NewSpoke, "check", check.Name)
+			failures = append(failures, check.Name)
+			continue
+		}
+
+		if err = applySmokeCheckWork(ctx, options.Client, work); err != nil {
+			logger.Error(err, "failed applying smoke check ManifestWork", "new-spoke", options.NewSpoke, "check", check.Name)
+			failures = append(failures, check.Name)
+			continue
+		}
+		logger.Info("smoke check ManifestWork applied", "new-spoke", options.NewSpoke, "check", check.Name)
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("failed applying smoke checks %v", failures)
+	}
+	return nil
+}
+
+// smokeCheckWork is used for building the ManifestWork for a smoke check, with feedback rules for its Jobs.
+func smokeCheckWork(check SmokeCheck, namespace string) (*workv1.ManifestWork, error) {
+	work := &workv1.ManifestWork{}
+	work.SetName(mcra.SmokeCheckManifestWorkPrefix + check.Name)
+	work.SetNamespace(namespace)
+	work.SetLabels(map[string]string{mcra.LabelSmokeCheck: check.Name})
+	work.SetAnnotations(map[string]string{mcra.AnnotationCreatedBy: mcra.AddonName})
+	work.Spec.Workload.Manifests = check.Manifests
+
+	for _, manifest := range check.Manifests {
+		obj := &unstructured.Unstructured{}
+		if err := json.Unmarshal(manifest.Raw, &obj.Object); err != nil {
+			return nil, err
+		}
+		if obj.GetKind() != "Job" {
+			continue
+		}
+
+		work.Spec.ManifestConfigs = append(work.Spec.ManifestConfigs, workv1.ManifestConfigOption{
+			ResourceIdentifier: workv1.ResourceIdentifier{
+				Group:     "batch",
+				Resource:  "jobs",
+				Name:      obj.GetName(),
+				Namespace: obj.GetNamespace(),
+			},
+			FeedbackRules: []workv1.FeedbackRule{{
+				Type:      workv1.JSONPathsType,
+				JsonPaths: []workv1.JsonPath{{Name: SmokeCheckSucceededFeedback, Path: ".status.succeeded"}},
+			}},
+		})
+	}
+	return work, nil
+}
+
+// applySmokeCheckWork is used for creating the smoke check ManifestWork, or updating it if already exists.
+func applySmokeCheckWork(ctx context.Context, c client.Client, work *workv1.ManifestWork) error {
+	if err := c.Create(ctx, work); err != nil {
+		if !errors.IsAlreadyExists(err) {
+			return err
+		}
+
+		existingWork := &workv1.ManifestWork{}
+		if err = c.Get(ctx, client.ObjectKeyFromObject(work), existingWork); err != nil {
+			return err
+		}
+		existingWork.SetLabels(work.GetLabels())
+		existingWork.Spec = work.Spec
+		return c.Update(ctx, existingWork)
+	}
+	return nil
+}
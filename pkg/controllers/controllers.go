@@ -9,6 +9,7 @@ import (
 	"fmt"
 	hivev1 "github.com/openshift/hive/apis/hive/v1"
 	apiv1 "github.com/rhecosystemappeng/multicluster-resiliency-addon/api/v1"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/controllers/exporter"
 	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/controllers/reconcilers"
 	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/controllers/webhooks"
 	corev1 "k8s.io/api/core/v1"
@@ -16,6 +17,10 @@ import (
 	"k8s.io/client-go/rest"
 	addonv1alpha1 "open-cluster-management.io/api/addon/v1alpha1"
 	clusterv1 "open-cluster-management.io/api/cluster/v1"
+	clusterv1beta1 "open-cluster-management.io/api/cluster/v1beta1"
+	clusterv1beta2 "open-cluster-management.io/api/cluster/v1beta2"
+	workv1 "open-cluster-management.io/api/work/v1"
+	"os"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -36,6 +41,9 @@ type Options struct {
 	ServiceAccount   string
 	ConfigMapName    string
 	EnableValidation bool
+	EventSink        string
+	EventSinkType    string
+	EventSinkTopic   string
 }
 
 // NewControllersWithOptions is used as a factory for creating a Controller instance with a given Options instance.
@@ -70,7 +78,28 @@ func (c *Controllers) Run(ctx context.Context, kubeConfig *rest.Config) error {
 		return err
 	}
 
-	if err = reconcilers.Setup(mgr, reconcilers.Options{ConfigMapName: c.Options.ConfigMapName}); err != nil {
+	// the exporter is optional, publishing the lifecycle events to the configured sink
+	var eventExporter *exporter.Exporter
+	if c.Options.EventSink != "" {
+		managerNamespace, exist := os.LookupEnv("POD_NAMESPACE")
+		if !exist {
+			return fmt.Errorf("unable to load manager namespace from POD_NAMESPACE")
+		}
+
+		sink, err := exporter.NewSink(c.Options.EventSinkType, c.Options.EventSink, c.Options.EventSinkTopic)
+		if err != nil {
+			logger.Error(err, "failed creating the event sink")
+			return err
+		}
+
+		eventExporter = exporter.NewExporter(mgr.GetClient(), mgr.GetAPIReader(), managerNamespace, sink)
+		if err = mgr.Add(eventExporter); err != nil {
+			logger.Error(err, "failed adding the event exporter")
+			return err
+		}
+	}
+
+	if err = reconcilers.Setup(mgr, reconcilers.Options{ConfigMapName: c.Options.ConfigMapName, Exporter: eventExporter}); err != nil {
 		logger.Error(err, "failed setup the controllers")
 		return err
 	}
@@ -120,5 +149,17 @@ func installTypes(scheme *runtime.Scheme) error {
 	if err := clusterv1.Install(scheme); err != nil {
 		return fmt.Errorf("failed installing ocm's types into the addon's scheme, %v", err)
 	}
+	// required for Placement and PlacementDecision
+	if err := clusterv1beta1.Install(scheme); err != nil {
+		return fmt.Errorf("failed installing ocm's placement types into the addon's scheme, %v", err)
+	}
+	// required for ManagedClusterSet and ManagedClusterSetBinding
+	if err := clusterv1beta2.Install(scheme); err != nil {
+		return fmt.Errorf("failed installing ocm's cluster set types into the addon's scheme, %v", err)
+	}
+	// required for ManifestWork
+	if err := workv1.Install(scheme); err != nil {
+		return fmt.Errorf("failed installing ocm's work types into the addon's scheme, %v", err)
+	}
 	return nil
 }
@@ -0,0 +1,220 @@
+// Copyright (c) 2023 Red Hat, Inc.
+
+package exporter
+
+// This file contains the Exporter publishing the Addon lifecycle as CloudEvents, through a persisted outbox.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	apiv1 "github.com/rhecosystemappeng/multicluster-resiliency-addon/api/v1"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/cloudevents"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/mcra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sort"
+	"time"
+)
+
+const (
+	// maxOutboxBytes is the maximum size of the events kept in the outbox, the oldest are dropped when exceeded. It
+	// keeps the outbox well below the 1MiB limit of a ConfigMap.
+	maxOutboxBytes = 512 * 1024
+	// drainInterval is the interval for delivering the events in the outbox.
+	drainInterval = 10 * time.Second
+)
+
+// the types of the published events, prefixed with cloudevents.TypePrefix
+const (
+	TypeAvailabilityChanged  = "availability.changed"
+	TypeReplacementStarted   = "replacement.started"
+	TypeReplacementRefused   = "replacement.refused"
+	TypeReplacementPerformed = "replacement.performed"
+	TypeReplacementValidated = "replacement.validated"
+	TypeActionCompleted      = "action.completed"
+)
+
+type (
+	// ClusterData is the data of the published events, describing the cluster the event is about. Fields are set per
+	// the event type.
+	ClusterData struct {
+		Spoke         string                    `json:"spoke"`
+		LogicalName   string                    `json:"logicalName,omitempty"`
+		Availability  apiv1.ClusterAvailability `json:"availability,omitempty"`
+		PreviousSpoke string                    `json:"previousSpoke,omitempty"`
+		Phase         apiv1.ReplacementPhase    `json:"phase,omitempty"`
+		Reason        string                    `json:"reason,omitempty"`
+		Action        *apiv1.ActionOutcome      `json:"action,omitempty"`
+	}
+
+	// Exporter is used for publishing events to a Sink with at-least-once delivery. Published events are persisted in
+	// an outbox ConfigMap in the manager namespace, and delivered in order by the Exporter running as a manager
+	// Runnable. Use NewExporter for instantiation. A nil Exporter publishes nothing.
+	Exporter struct {
+		client    client.Client
+		reader    client.Reader
+		namespace string
+		sink      Sink
+	}
+)
+
+// NewExporter is used as a factory for creating an Exporter persisting the outbox in the namespace. The reader is used
+// for reading the outbox uncached.
+func NewExporter(c client.Client, reader client.Reader, namespace string, sink Sink) *Exporter {
+	return &Exporter{client: c, reader: reader, namespace: namespace, sink: sink}
+}
+
+// Publish is used for adding an event to the outbox, for delivery by the Exporter. The subject of the event is the
+// data's Spoke. Failures are logged, the event is lost.
+func (e *Exporter) Publish(ctx context.Context, eventType string, data ClusterData) {
+	if e == nil {
+		return
+	}
+	logger := log.FromContext(ctx)
+
+	now := time.Now()
+	event, err := cloudevents.New(eventType, data.Spoke, now, data)
+	if err != nil {
+		logger.Error(err, "failed serializing event data", "type", eventType)
+		return
+	}
+	serialized, err := json.Marshal(event)
+	if err != nil {
+		logger.Error(err, "failed serializing event", "type", eventType)
+		return
+	}
+
+	// keys are ordered by the time of publishing, and unique by the event id
+	key := fmt.Sprintf("%020d-%s", now.UnixNano(), event.ID)
+	err = e.updateOutbox(ctx, func(outbox *corev1.ConfigMap) {
+		outbox.Data[key] = string(serialized)
+		if dropped := dropOldest(outbox.Data, maxOutboxBytes); dropped > 0 {
+			logger.Info("outbox is full, dropped the oldest events", "dropped", dropped)
+		}
+	})
+	if err != nil {
+		logger.Error(err, "failed adding event to the outbox", "type", eventType)
+	}
+}
+
+// Start is used for delivering the events in the outbox every drainInterval, until the context is done.
+func (e *Exporter) Start(ctx context.Context) error {
+	wait.UntilWithContext(ctx, e.drain, drainInterval)
+	return nil
+}
+
+// NeedLeaderElection returns true, only the leader delivers the events.
+func (e *Exporter) NeedLeaderElection() bool {
+	return true
+}
+
+// drain is used for delivering the events in the outbox in order. Delivered events are removed from the outbox. The
+// first failure stops the drain, keeping the order, the remaining events are retried on the next drain.
+func (e *Exporter) drain(ctx context.Context) {
+	logger := log.FromContext(ctx)
+
+	outbox := &corev1.ConfigMap{}
+	if err := e.reader.Get(ctx, types.NamespacedName{Namespace: e.namespace, Name: mcra.OutboxConfigMapName}, outbox); err != nil {
+		if !errors.IsNotFound(err) {
+			logger.Error(err, "failed fetching the outbox")
+		}
+		return
+	}
+
+	var delivered []string
+	for _, key := range sortedKeys(outbox.Data) {
+		event := cloudevents.CloudEvent{}
+		if err := json.Unmarshal([]byte(outbox.Data[key]), &event); err != nil {
+			// a malformed event will never be delivered, drop it
+			logger.Error(err, "dropping malformed event", "key", key)
+			delivered = append(delivered, key)
+			continue
+		}
+
+		if err := e.sink.Send(ctx, event); err != nil {
+			logger.Error(err, "failed delivering event, retrying on the next drain", "type", event.Type, "id", event.ID)
+			break
+		}
+		delivered = append(delivered, key)
+	}
+
+	if len(delivered) == 0 {
+		return
+	}
+	err := e.updateOutbox(ctx, func(outbox *corev1.ConfigMap) {
+		for _, key := range delivered {
+			delete(outbox.Data, key)
+		}
+	})
+	if err != nil {
+		// the delivered events remain in the outbox and will be delivered again
+		logger.Error(err, "failed removing delivered events from the outbox")
+		return
+	}
+	logger.Info("events delivered", "delivered", len(delivered))
+}
+
+// updateOutbox is used for applying the update function on the outbox ConfigMap, creating it if not found. Conflicts
+// are retried.
+func (e *Exporter) updateOutbox(ctx context.Context, update func(outbox *corev1.ConfigMap)) error {
+	subject := types.NamespacedName{Namespace: e.namespace, Name: mcra.OutboxConfigMapName}
+
+	conflicting := func(err error) bool {
+		return errors.IsConflict(err) || errors.IsAlreadyExists(err)
+	}
+
+	return retry.OnError(retry.DefaultRetry, conflicting, func() error {
+		outbox := &corev1.ConfigMap{}
+		if err := e.reader.Get(ctx, subject, outbox); err != nil {
+			if !errors.IsNotFound(err) {
+				return err
+			}
+
+			outbox.SetName(subject.Name)
+			outbox.SetNamespace(subject.Namespace)
+			outbox.Data = map[string]string{}
+			update(outbox)
+			return e.client.Create(ctx, outbox)
+		}
+
+		if outbox.Data == nil {
+			outbox.Data = map[string]string{}
+		}
+		update(outbox)
+		return e.client.Update(ctx, outbox)
+	})
+}
+
+// dropOldest is used for removing the oldest entries while the size of the keys and values exceeds the maximum bytes.
+// Returns the number of removed entries.
+func dropOldest(data map[string]string, maxBytes int) int {
+	size := 0
+	for key, value := range data {
+		size += len(key) + len(value)
+	}
+
+	keys := sortedKeys(data)
+	dropped := 0
+	for size > maxBytes && dropped < len(keys) {
+		size -= len(keys[dropped]) + len(data[keys[dropped]])
+		delete(data, keys[dropped])
+		dropped++
+	}
+	return dropped
+}
+
+// sortedKeys returns the keys of the outbox data sorted, the oldest first.
+func sortedKeys(data map[string]string) []string {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2023 Red Hat, Inc.
+
+package exporter
+
+import (
+	"k8s.io/apimachinery/pkg/api/equality"
+	"testing"
+)
+
+func TestDropOldest(t *testing.T) {
+	// every entry is 10 bytes, a 4 bytes key and a 6 bytes value
+	tests := []struct {
+		name        string
+		data        map[string]string
+		maxBytes    int
+		wantDropped int
+		wantData    map[string]string
+	}{
+		{
+			name:     "empty outbox",
+			data:     map[string]string{},
+			maxBytes: 10,
+			wantData: map[string]string{},
+		},
+		{
+			name:     "within the maximum",
+			data:     map[string]string{"0001": "event1", "0002": "event2"},
+			maxBytes: 20,
+			wantData: map[string]string{"0001": "event1", "0002": "event2"},
+		},
+		{
+			name:        "oldest dropped first",
+			data:        map[string]string{"0003": "event3", "0001": "event1", "0002": "event2"},
+			maxBytes:    20,
+			wantDropped: 1,
+			wantData:    map[string]string{"0002": "event2", "0003": "event3"},
+		},
+		{
+			name:        "dropped until within the maximum",
+			data:        map[string]string{"0001": "event1", "0002": "event2", "0003": "event3"},
+			maxBytes:    15,
+			wantDropped: 2,
+			wantData:    map[string]string{"0003": "event3"},
+		},
+		{
+			name:        "large event drops all",
+			data:        map[string]string{"0001": "event1", "0002": "a-very-large-event"},
+			maxBytes:    15,
+			wantDropped: 2,
+			wantData:    map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if dropped := dropOldest(tt.data, tt.maxBytes); dropped != tt.wantDropped {
+				t.Errorf("dropOldest() = %d, want %d", dropped, tt.wantDropped)
+			}
+			if !equality.Semantic.DeepEqual(tt.data, tt.wantData) {
+				t.Errorf("dropOldest() left %v, want %v", tt.data, tt.wantData)
+			}
+		})
+	}
+}
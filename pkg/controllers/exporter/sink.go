@@ -0,0 +1,107 @@
+// Copyright (c) 2023 Red Hat, Inc.
+
+package exporter
+
+// This file contains the sinks events are delivered to.
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/cloudevents"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// sendTimeout is the time a sink is allowed to respond.
+const sendTimeout = 10 * time.Second
+
+const (
+	SinkHTTP  = "http"
+	SinkKafka = "kafka"
+)
+
+// Sink is used for delivering events.
+type Sink interface {
+	// Send is used for delivering an event, returns an error if not acknowledged.
+	Send(ctx context.Context, event cloudevents.CloudEvent) error
+}
+
+// NewSink is used as a factory for creating a Sink by type. The HTTP sink POSTs the events in the CloudEvents
+// structured content mode, i.e. to a Knative Broker. The Kafka sink produces the events to the topic through a Kafka
+// REST proxy, i.e. a local Strimzi Kafka Bridge.
+func NewSink(sinkType, endpoint, topic string) (Sink, error) {
+	if _, err := url.ParseRequestURI(endpoint); err != nil {
+		return nil, fmt.Errorf("invalid event sink url %s, %v", endpoint, err)
+	}
+
+	switch sinkType {
+	case SinkHTTP:
+		return &HTTPSink{URL: endpoint}, nil
+	case SinkKafka:
+		if topic == "" {
+			return nil, fmt.Errorf("the kafka event sink requires a topic")
+		}
+		return &KafkaSink{URL: endpoint, Topic: topic}, nil
+	default:
+		return nil, fmt.Errorf("unknown event sink type %s", sinkType)
+	}
+}
+
+// HTTPSink is used for POSTing events to an HTTP endpoint, a 2xx response status is an acknowledgment.
+type HTTPSink struct {
+	URL string
+}
+
+// Send is used for POSTing the event as a structured CloudEvent.
+func (s *HTTPSink) Send(ctx context.Context, event cloudevents.CloudEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return post(ctx, s.URL, "application/cloudevents+json", body)
+}
+
+// KafkaSink is used for producing events to a Kafka topic through a Kafka REST proxy, a 2xx response status is an
+// acknowledgment. The events are keyed by their subject, keeping the events of a cluster in order.
+type KafkaSink struct {
+	URL   string
+	Topic string
+}
+
+// Send is used for producing the event as a structured CloudEvent record.
+func (s *KafkaSink) Send(ctx context.Context, event cloudevents.CloudEvent) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"records": []map[string]interface{}{{"key": event.Subject, "value": event}},
+	})
+	if err != nil {
+		return err
+	}
+	return post(ctx, fmt.Sprintf("%s/topics/%s", strings.TrimSuffix(s.URL, "/"), url.PathEscape(s.Topic)), "application/vnd.kafka.json.v2+json", body)
+}
+
+// post is used for POSTing the body to the URL, returns an error for non 2xx response statuses.
+func post(ctx context.Context, endpoint, contentType string, body []byte) error {
+	sendCtx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(sendCtx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", contentType)
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("event sink responded with %s", response.Status)
+	}
+	return nil
+}
@@ -8,14 +8,19 @@ import (
 	"context"
 	"fmt"
 	apiv1 "github.com/rhecosystemappeng/multicluster-resiliency-addon/api/v1"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/controllers/exporter"
 	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/mcra"
 	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/metrics"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/notify"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	addonv1alpha1 "open-cluster-management.io/api/addon/v1alpha1"
+	"os"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -27,7 +32,10 @@ import (
 // ManagedClusterAddOn CRs.
 type AddonReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Reader   client.Reader
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	Options
 }
 
 // setupWithManager is used for setting up the controller named 'mcra-managed-cluster-agent-controller' with the manager.
@@ -46,7 +54,7 @@ func (r *AddonReconciler) setupWithManager(mgr ctrl.Manager) error {
 // +kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=get;create
 // +kubebuilder:rbac:groups=certificates.k8s.io,resources=certificatesigningrequests;certificatesigningrequests/approval,verbs=get;list;watch;create;update
 // +kubebuilder:rbac:groups=certificates.k8s.io,resources=signers,verbs=approve
-// +kubebuilder:rbac:groups=cluster.open-cluster-management.io,resources=managedclusters,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cluster.open-cluster-management.io,resources=managedclusters,verbs=get;list;watch;patch
 // +kubebuilder:rbac:groups=work.open-cluster-management.io,resources=manifestworks,verbs=create;update;get;list;watch;delete;deletecollection;patch
 // +kubebuilder:rbac:groups=addon.open-cluster-management.io,resources=clustermanagementaddons,verbs=get;list;watch
 // +kubebuilder:rbac:groups=addon.open-cluster-management.io,resources=clustermanagementaddons/finalizers,verbs=update
@@ -120,10 +128,37 @@ func (r *AddonReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 		rc.Status.PreviousStatus = rc.Status.CurrentStatus
 		rc.Status.CurrentStatus = currentStatus
 
+		// ResilientClusters created by ClaimReconciler for recording a replacement are adopted on their first update
+		if len(rc.GetOwnerReferences()) == 0 {
+			if err := controllerutil.SetOwnerReference(mca, rc, r.Scheme); err != nil {
+				logger.Error(err, "failed to set ManagedClusterAddon as owner on ResilientCluster")
+				return ctrl.Result{}, err
+			}
+		}
+		if rc.Status.InitialStatus.Availability == "" {
+			rc.Status.InitialStatus = currentStatus
+		}
+		if rc.Status.LogicalName == "" {
+			setLogicalName(rc, logicalNameOf(rc))
+			if err := labelManagedCluster(ctx, r.Client, rc.Namespace, rc.Status.LogicalName); err != nil {
+				logger.Error(err, fmt.Sprintf("%s ManagedCluster labeling failed", rc.Namespace))
+			}
+		}
+
 		if err := r.Client.Update(ctx, rc); err != nil {
 			logger.Error(err, fmt.Sprintf("%s ResilientCluster update failed", rcSubject.String()))
 			return ctrl.Result{}, err
 		}
+
+		if rc.Status.CurrentStatus.Availability != rc.Status.PreviousStatus.Availability {
+			recordAvailability(r.Recorder, rc)
+			r.publishAvailability(ctx, rc)
+		}
+		// the availability changes once, the following updates keep the previous status not available
+		// the availability is tracked while suspended, acting on it and notifying is frozen
+		if requiresNewClaim(rc) && !isSuspended(rc) {
+			r.notifyUnavailable(ctx, rc)
+		}
 	} else {
 		// ResilientCluster doesn't exist, we need to create it
 		rc.SetName(rcSubject.Name)
@@ -134,6 +169,12 @@ func (r *AddonReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 			return ctrl.Result{}, err
 		}
 
+		// new instances are logically named after their cluster-namespace, replacements will override it
+		setLogicalName(rc, rcSubject.Namespace)
+		if err := labelManagedCluster(ctx, r.Client, rcSubject.Namespace, rcSubject.Namespace); err != nil {
+			logger.Error(err, fmt.Sprintf("%s ManagedCluster labeling failed", rcSubject.Namespace))
+		}
+
 		// for new instances, the current status is also the initial status
 		// new instances do not require a PreviousStatus
 		rc.Status.InitialStatus = currentStatus
@@ -143,11 +184,59 @@ func (r *AddonReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 			logger.Error(err, fmt.Sprintf("%s ResilientCluster creation failed", rcSubject.String()))
 			return ctrl.Result{}, err
 		}
+		recordAvailability(r.Recorder, rc)
+		r.publishAvailability(ctx, rc)
 	}
 
 	return ctrl.Result{}, nil
 }
 
+// notifyUnavailable is used for notifying the Spoke became unavailable. Notifications are best-effort, failing to load
+// the configuration is logged. Notifiers are only accepted from the manager configuration, as their headers secrets
+// are read from the manager namespace.
+func (r *AddonReconciler) notifyUnavailable(ctx context.Context, rc *apiv1.ResilientCluster) {
+	logger := log.FromContext(ctx)
+
+	managerNamespace, exist := os.LookupEnv("POD_NAMESPACE")
+	if !exist {
+		logger.Info("unable to load manager namespace from POD_NAMESPACE, not notifying")
+		return
+	}
+
+	managerConfig, err := loadManagerConfiguration(ctx, r.Client, r.ConfigMapName, managerNamespace)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			logger.Error(err, "unable to load manager configuration, not notifying")
+		}
+		return
+	}
+
+	notify.Send(ctx, r.Reader, managerNamespace, managerConfig.Notifiers, notify.Event{
+		Type:        notify.EventSpokeUnavailable,
+		Spoke:       rc.Namespace,
+		LogicalName: logicalNameOf(rc),
+		Message:     replacementReason(rc),
+	})
+}
+
+// recordAvailability is used for recording an event on the ResilientCluster reporting its current availability.
+func recordAvailability(recorder record.EventRecorder, rc *apiv1.ResilientCluster) {
+	if rc.Status.CurrentStatus.Availability == apiv1.ClusterAvailable {
+		recorder.Eventf(rc, corev1.EventTypeNormal, "ClusterAvailable", "cluster %s is available", rc.Namespace)
+	} else {
+		recorder.Eventf(rc, corev1.EventTypeWarning, "ClusterNotAvailable", "cluster %s is not available", rc.Namespace)
+	}
+}
+
+// publishAvailability is used for publishing the current availability of the ResilientCluster.
+func (r *AddonReconciler) publishAvailability(ctx context.Context, rc *apiv1.ResilientCluster) {
+	r.Exporter.Publish(ctx, exporter.TypeAvailabilityChanged, exporter.ClusterData{
+		Spoke:        rc.Namespace,
+		LogicalName:  rc.Status.LogicalName,
+		Availability: rc.Status.CurrentStatus.Availability,
+	})
+}
+
 // generateCurrentClusterStatus is used for generating a ClusterStatus from based on a ManagedClusterAddon. For future
 // features, this is potentially where we can add further logic for determining whether of not the Spoke is available.
 func generateCurrentClusterStatus(mca *addonv1alpha1.ManagedClusterAddOn) apiv1.ClusterStatus {
@@ -170,6 +259,12 @@ func generateCurrentClusterStatus(mca *addonv1alpha1.ManagedClusterAddOn) apiv1.
 // init is registering the AddonReconciler setup function for execution.
 func init() {
 	reconcilerFuncs = append(reconcilerFuncs, func(mgr manager.Manager, options Options) error {
-		return (&AddonReconciler{Client: mgr.GetClient(), Scheme: mgr.GetScheme()}).setupWithManager(mgr)
+		return (&AddonReconciler{
+			Client:   mgr.GetClient(),
+			Reader:   mgr.GetAPIReader(),
+			Scheme:   mgr.GetScheme(),
+			Recorder: mgr.GetEventRecorderFor("mcra-addon-controller"),
+			Options:  options,
+		}).setupWithManager(mgr)
 	})
 }
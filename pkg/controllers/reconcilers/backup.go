@@ -0,0 +1,52 @@
+// Copyright (c) 2023 Red Hat, Inc.
+
+package reconcilers
+
+// This file contains utility functions for loading the backup target used before replacing clusters.
+
+import (
+	"context"
+	"fmt"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/backup"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	backupTargetDirectory = "directory"
+	backupTargetS3        = "s3"
+)
+
+// loadBackupTarget is used for building the backup target from the Config. The credentials for the S3 target are loaded
+// from a Secret in the manager namespace, read uncached. Returns nil if no backup target is configured.
+func loadBackupTarget(ctx context.Context, c client.Reader, config Config, managerNamespace string) (backup.Target, error) {
+	switch config.BackupTarget {
+	case "":
+		return nil, nil
+	case backupTargetDirectory:
+		if config.BackupDirectory == "" {
+			return nil, fmt.Errorf("backup_directory is required for the %s backup target", backupTargetDirectory)
+		}
+		return &backup.DirectoryTarget{Path: config.BackupDirectory}, nil
+	case backupTargetS3:
+		if config.BackupS3Endpoint == "" || config.BackupS3Bucket == "" || config.BackupS3Secret == "" {
+			return nil, fmt.Errorf("backup_s3_endpoint, backup_s3_bucket, and backup_s3_secret are required for the %s backup target", backupTargetS3)
+		}
+
+		secret := &corev1.Secret{}
+		if err := c.Get(ctx, types.NamespacedName{Namespace: managerNamespace, Name: config.BackupS3Secret}, secret); err != nil {
+			return nil, fmt.Errorf("failed fetching backup secret %s, %v", config.BackupS3Secret, err)
+		}
+
+		return &backup.S3Target{
+			Endpoint:        config.BackupS3Endpoint,
+			Bucket:          config.BackupS3Bucket,
+			Region:          config.BackupS3Region,
+			AccessKeyID:     string(secret.Data["aws_access_key_id"]),
+			SecretAccessKey: string(secret.Data["aws_secret_access_key"]),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown backup target %s", config.BackupTarget)
+	}
+}
@@ -6,26 +6,41 @@ package reconcilers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	hivev1 "github.com/openshift/hive/apis/hive/v1"
+	apiv1 "github.com/rhecosystemappeng/multicluster-resiliency-addon/api/v1"
 	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/controllers/actions"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/controllers/exporter"
 	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/mcra"
 	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/metrics"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/notify"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	"os"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 )
 
+// maxReplacementHistory is the maximum number of replacements kept in a ResilientCluster's history.
+const maxReplacementHistory = 10
+
 // ClaimReconciler is a receiver representing the MultiCluster-Resiliency-Addon operator reconciler for
 // ClusterClaim CRs.
 type ClaimReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Reader    client.Reader
+	Scheme    *runtime.Scheme
+	Discovery discovery.DiscoveryInterface
+	Recorder  record.EventRecorder
 	Options
 }
 
@@ -42,8 +57,19 @@ func (r *ClaimReconciler) setupWithManager(mgr ctrl.Manager) error {
 // +kubebuilder:rbac:groups=hive.openshift.io,resources=clusterpools,verbs=get;list;watch
 // +kubebuilder:rbac:groups=hive.openshift.io,resources=clusterclaims,verbs=get;list;watch;create;update
 // +kubebuilder:rbac:groups=hive.openshift.io,resources=clusterclaims/finalizers,verbs=update
-// +kubebuilder:rbac:groups=hive.openshift.io,resources=clusterdeployments,verbs=get;list;watch;create;delete
+// +kubebuilder:rbac:groups=hive.openshift.io,resources=clusterdeployments,verbs=get;list;watch;create;delete;patch
 // +kubebuilder:rbac:groups=cluster.open-cluster-management.io,resources=managedclusters,verbs=get;list;watch;update;delete;patch
+// +kubebuilder:rbac:groups=cluster.open-cluster-management.io,resources=managedclustersets;managedclustersetbindings;placementdecisions,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cluster.open-cluster-management.io,resources=managedclustersets/join,verbs=create
+// +kubebuilder:rbac:groups=cluster.open-cluster-management.io,resources=placements,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=policy.open-cluster-management.io,resources=placementbindings;policies,verbs=get;list;watch
+// +kubebuilder:rbac:groups=apps.open-cluster-management.io,resources=placementrules,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=apps.open-cluster-management.io,resources=gitopsclusters;subscriptions,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;delete
+// +kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=get;list
+// +kubebuilder:rbac:groups=hive.openshift.io,resources=machinepools;syncsets,verbs=get;list
+// +kubebuilder:rbac:groups=internal.open-cluster-management.io,resources=managedclusterinfos,verbs=get;list
+// +kubebuilder:rbac:groups=agent.open-cluster-management.io,resources=klusterletaddonconfigs,verbs=get;list
 
 // Reconcile is watching ClusterClaim CRs updating the appropriate ResilientCluster CRs, and deleting replaced
 // ClusterClaim CRs. Note, further permissions are listed in ClusterReconciler.Reconcile and AddonReconciler.Reconcile.
@@ -94,27 +120,253 @@ func (r *ClaimReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 	// the NEW spoke name is the target namespace in which the ClusterDeployment was created
 	newSpokeName := claim.Spec.Namespace
 
-	// perform all actions required for replacing a cluster
-	actions.PerformReplace(ctx, actions.Options{Client: r.Client, OldSpoke: oldSpokeName, NewSpoke: newSpokeName, ConfigMapName: r.Options.ConfigMapName})
+	// the logical name was set as a label when we created the ClusterClaim in ClusterReconciler
+	logicalName, found := claim.GetLabels()[mcra.LabelLogicalName]
+	if !found {
+		logicalName = oldSpokeName
+	}
+
+	// the replacement is recorded before the annotation is removed, if recorded, the OLD spoke is already gone and only
+	// the removal is retried, reading directly from the API server so a stale cache will not perform the actions again
+	newRc := &apiv1.ResilientCluster{}
+	if err := r.Reader.Get(ctx, types.NamespacedName{Namespace: newSpokeName, Name: newSpokeName}, newRc); err != nil && !errors.IsNotFound(err) {
+		logger.Error(err, "failed fetching new ResilientCluster", "new-spoke", newSpokeName)
+		return ctrl.Result{}, err
+	}
+	if recorded := recordedReplacement(newRc, claim.Name); recorded != nil {
+		logger.Info("replacement already recorded, not replacing again", "old-spoke", oldSpokeName, "new-spoke", newSpokeName)
+		return ctrl.Result{}, r.completeReplacement(ctx, claim, newSpokeName, logicalName, recorded)
+	}
+
+	managerNamespace, exist := os.LookupEnv("POD_NAMESPACE")
+	if !exist {
+		return ctrl.Result{}, fmt.Errorf("unable to load manager namespace from POD_NAMESPACE")
+	}
+
+	// the configuration is loaded from the OLD spoke namespace, before the actions moves it to the NEW one
+	config, err := loadConfiguration(ctx, r.Client, r.ConfigMapName, oldSpokeName, managerNamespace)
+	if err != nil {
+		logger.Error(err, "unable to load configuration")
+		return ctrl.Result{}, err
+	}
+
+	// the settings granting access to the manager namespace, or applying arbitrary manifests to the NEW spoke, are only
+	// accepted from the manager configuration
+	managerConfig, err := loadManagerConfiguration(ctx, r.Client, r.ConfigMapName, managerNamespace)
+	if err != nil && !errors.IsNotFound(err) {
+		logger.Error(err, "unable to load manager configuration")
+		return ctrl.Result{}, err
+	}
+
+	// the backup target is loaded before the actions, as the destructive ones require the backup to succeed
+	backupTarget, err := loadBackupTarget(ctx, r.Reader, managerConfig, managerNamespace)
+	if err != nil {
+		logger.Error(err, "unable to load backup target")
+		return ctrl.Result{}, err
+	}
+
+	// the OLD ResilientCluster is deleted by the actions, its lineage and history are carried over to the NEW one
+	lineage := []string{oldSpokeName}
+	var history []apiv1.ReplacementStatus
+	oldRc := &apiv1.ResilientCluster{}
+	if err = r.Client.Get(ctx, types.NamespacedName{Namespace: oldSpokeName, Name: oldSpokeName}, oldRc); err == nil {
+		lineage = append(append([]string{}, oldRc.Status.Lineage...), oldSpokeName)
+		history = oldRc.Status.History
+	} else if !errors.IsNotFound(err) {
+		logger.Error(err, "failed fetching old ResilientCluster", "old-spoke", oldSpokeName)
+		return ctrl.Result{}, err
+	}
+
+	// the actions are not performed while the OLD spoke is suspended, the claim is retried once resumed
+	if isSuspended(oldRc) {
+		logger.Info("old spoke is suspended, not replacing", "old-spoke", oldSpokeName)
+		return ctrl.Result{RequeueAfter: suspendedRequeueInterval}, nil
+	}
+
+	r.Recorder.Eventf(claim, corev1.EventTypeNormal, "ClaimReady", "cluster %s is ready for replacing cluster %s", newSpokeName, oldSpokeName)
+	notify.Send(ctx, r.Reader, managerNamespace, managerConfig.Notifiers, notify.Event{
+		Type:        notify.EventClaimReady,
+		Spoke:       oldSpokeName,
+		NewSpoke:    newSpokeName,
+		LogicalName: logicalName,
+		Message:     fmt.Sprintf("claim %s is ready", claim.Name),
+	})
+
+	// perform all actions required for replacing a cluster, the actions populate the replacement record
+	replacement := &apiv1.ReplacementStatus{
+		PreviousSpoke: oldSpokeName,
+		Reason:        claim.GetAnnotations()[mcra.AnnotationReplacementReason],
+		ClaimName:     claim.Name,
+		PoolName:      claim.Spec.ClusterPoolName,
+		StartTime:     claim.CreationTimestamp,
+	}
+	replacement.Actions = actions.PerformReplace(ctx, actions.Options{
+		Client:                     r.Client,
+		Reader:                     r.Reader,
+		Discovery:                  r.Discovery,
+		Recorder:                   r.Recorder,
+		OldSpoke:                   oldSpokeName,
+		NewSpoke:                   newSpokeName,
+		ConfigMapName:              r.Options.ConfigMapName,
+		LogicalName:                logicalName,
+		AddonsAllowList:            config.AddonsAllowList,
+		AddonsDenyList:             config.AddonsDenyList,
+		MigrationRules:             config.MigrationRules,
+		SecretsSelector:            config.SecretsSelector,
+		SecretsNames:               config.SecretsNames,
+		ManagedClusterInclude:      config.ManagedClusterInclude,
+		ManagedClusterExclude:      config.ManagedClusterExclude,
+		BackupTarget:               backupTarget,
+		BackupSecrets:              managerConfig.BackupSecrets,
+		ClusterDeploymentRetention: config.ClusterDeploymentRetention,
+		SmokeChecks:                managerConfig.SmokeChecks,
+		Replacement:                replacement,
+	})
+
+	for _, outcome := range replacement.Actions {
+		r.Exporter.Publish(ctx, exporter.TypeActionCompleted, exporter.ClusterData{
+			Spoke:         newSpokeName,
+			LogicalName:   logicalName,
+			PreviousSpoke: oldSpokeName,
+			Action:        &outcome,
+		})
+		if !outcome.Succeeded {
+			notify.Send(ctx, r.Reader, managerNamespace, managerConfig.Notifiers, notify.Event{
+				Type:        notify.EventActionFailed,
+				Spoke:       oldSpokeName,
+				NewSpoke:    newSpokeName,
+				LogicalName: logicalName,
+				Message:     fmt.Sprintf("action %s failed, %s", outcome.Action, outcome.Message),
+			})
+		}
+	}
 
-	// when done, remove the annotation
+	// the pre-failover hooks were invoked by the ClusterReconciler, the post-failover hooks are invoked by the
+	// ValidationReconciler
+	if serialized, found := claim.GetAnnotations()[mcra.AnnotationPreFailoverHooks]; found {
+		if err = json.Unmarshal([]byte(serialized), &replacement.Hooks); err != nil {
+			logger.Error(err, "failed parsing pre-failover hooks")
+		}
+	}
+
+	replacement.FinishTime = metav1.Now()
+	// the NEW spoke is validated by the ValidationReconciler
+	replacement.Phase = apiv1.ReplacementValidating
+
+	// record the replacement, the logical name, the lineage, and the history on the NEW spoke's ResilientCluster
+	err = r.recordReplacement(ctx, newSpokeName, func(rc *apiv1.ResilientCluster) {
+		rc.Status.Replacement = replacement
+		rc.Status.Lineage = lineage
+		rc.Status.History = appendHistory(history, *replacement)
+		setLogicalName(rc, logicalName)
+	})
+	if err != nil {
+		logger.Error(err, "failed recording replacement", "new-spoke", newSpokeName)
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, r.completeReplacement(ctx, claim, newSpokeName, logicalName, replacement)
+}
+
+// completeReplacement is used for removing the previous spoke annotation from the ClusterClaim once the replacement was
+// recorded, ending its reconciliation. The annotation is removed with a patch, so a stale claim will not conflict.
+func (r *ClaimReconciler) completeReplacement(ctx context.Context, claim *hivev1.ClusterClaim, newSpokeName, logicalName string, replacement *apiv1.ReplacementStatus) error {
+	logger := log.FromContext(ctx)
+
+	claimPatch := client.MergeFrom(claim.DeepCopy())
 	annotations := claim.GetAnnotations()
 	delete(annotations, mcra.AnnotationPreviousSpoke)
 	claim.SetAnnotations(annotations)
 
-	if err := r.Client.Update(ctx, claim); err != nil {
-		logger.Error(err, fmt.Sprintf("%s failed removing finalizer and annotation from claim", claimSubject.String()))
-		return ctrl.Result{}, err
+	if err := r.Client.Patch(ctx, claim, claimPatch); err != nil {
+		logger.Error(err, fmt.Sprintf("%s/%s failed removing annotation from claim", claim.Namespace, claim.Name))
+		return err
 	}
 
+	oldSpokeName := replacement.PreviousSpoke
 	metrics.NewSpokeReady.WithLabelValues(oldSpokeName, newSpokeName).Inc()
+	r.Recorder.Eventf(claim, corev1.EventTypeNormal, "ClusterReplaced", "cluster %s replaced by %s", oldSpokeName, newSpokeName)
+	r.Exporter.Publish(ctx, exporter.TypeReplacementPerformed, exporter.ClusterData{
+		Spoke:         newSpokeName,
+		LogicalName:   logicalName,
+		PreviousSpoke: oldSpokeName,
+		Phase:         replacement.Phase,
+		Reason:        replacement.Reason,
+	})
+	return nil
+}
 
-	return ctrl.Result{}, nil
+// recordedReplacement is used for finding the replacement performed for a ClusterClaim in a ResilientCluster's current
+// replacement or history. Returns nil if not recorded.
+func recordedReplacement(rc *apiv1.ResilientCluster, claimName string) *apiv1.ReplacementStatus {
+	if rc.Status.Replacement != nil && rc.Status.Replacement.ClaimName == claimName {
+		return rc.Status.Replacement
+	}
+	for i := len(rc.Status.History) - 1; i >= 0; i-- {
+		if rc.Status.History[i].ClaimName == claimName {
+			return &rc.Status.History[i]
+		}
+	}
+	return nil
+}
+
+// recordReplacement is used for applying the record function on the NEW spoke's ResilientCluster. The NEW spoke's
+// Agent might not have reported yet, in which case the ResilientCluster is created, and will be adopted by the
+// AddonReconciler once the NEW spoke's ManagedClusterAddOn is reconciled. Conflicts with the AddonReconciler are
+// retried.
+func (r *ClaimReconciler) recordReplacement(ctx context.Context, newSpokeName string, record func(rc *apiv1.ResilientCluster)) error {
+	rcSubject := types.NamespacedName{
+		Namespace: newSpokeName,
+		Name:      newSpokeName,
+	}
+
+	conflicting := func(err error) bool {
+		return errors.IsConflict(err) || errors.IsAlreadyExists(err)
+	}
+
+	return retry.OnError(retry.DefaultRetry, conflicting, func() error {
+		rc := &apiv1.ResilientCluster{}
+		if err := r.Client.Get(ctx, rcSubject, rc); err != nil {
+			if !errors.IsNotFound(err) {
+				return err
+			}
+
+			rc.SetName(rcSubject.Name)
+			rc.SetNamespace(rcSubject.Namespace)
+			rc.SetFinalizers([]string{mcra.FinalizerResilientClusterCleanup})
+			record(rc)
+			return r.Client.Create(ctx, rc)
+		}
+
+		record(rc)
+		return r.Client.Update(ctx, rc)
+	})
+}
+
+// appendHistory is used for appending a replacement to the history, dropping the oldest replacements exceeding
+// maxReplacementHistory.
+func appendHistory(history []apiv1.ReplacementStatus, replacement apiv1.ReplacementStatus) []apiv1.ReplacementStatus {
+	history = append(append([]apiv1.ReplacementStatus{}, history...), replacement)
+	if len(history) > maxReplacementHistory {
+		history = history[len(history)-maxReplacementHistory:]
+	}
+	return history
 }
 
 // init is registering the ClaimReconciler setup function for execution.
 func init() {
 	reconcilerFuncs = append(reconcilerFuncs, func(mgr manager.Manager, options Options) error {
-		return (&ClaimReconciler{Client: mgr.GetClient(), Scheme: mgr.GetScheme(), Options: options}).setupWithManager(mgr)
+		discoveryClient, err := discovery.NewDiscoveryClientForConfig(mgr.GetConfig())
+		if err != nil {
+			return err
+		}
+		return (&ClaimReconciler{
+			Client:    mgr.GetClient(),
+			Reader:    mgr.GetAPIReader(),
+			Scheme:    mgr.GetScheme(),
+			Discovery: discoveryClient,
+			Recorder:  mgr.GetEventRecorderFor("mcra-claim-controller"),
+			Options:   options,
+		}).setupWithManager(mgr)
 	})
 }
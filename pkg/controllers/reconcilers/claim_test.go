@@ -0,0 +1,127 @@
+// Copyright (c) 2023 Red Hat, Inc.
+
+package reconcilers
+
+import (
+	"context"
+	hivev1 "github.com/openshift/hive/apis/hive/v1"
+	apiv1 "github.com/rhecosystemappeng/multicluster-resiliency-addon/api/v1"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/mcra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"testing"
+)
+
+func TestRecordedReplacement(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    apiv1.ResilientClusterStatus
+		claimName string
+		want      string
+	}{
+		{name: "nothing recorded", claimName: "claim1", want: ""},
+		{
+			name:      "current replacement",
+			status:    apiv1.ResilientClusterStatus{Replacement: &apiv1.ReplacementStatus{ClaimName: "claim1", PreviousSpoke: "spoke1"}},
+			claimName: "claim1",
+			want:      "spoke1",
+		},
+		{
+			name: "replacement in history",
+			status: apiv1.ResilientClusterStatus{
+				Replacement: &apiv1.ReplacementStatus{ClaimName: "claim2", PreviousSpoke: "spoke2"},
+				History:     []apiv1.ReplacementStatus{{ClaimName: "claim1", PreviousSpoke: "spoke1"}, {ClaimName: "claim2", PreviousSpoke: "spoke2"}},
+			},
+			claimName: "claim1",
+			want:      "spoke1",
+		},
+		{
+			name:      "other claim",
+			status:    apiv1.ResilientClusterStatus{Replacement: &apiv1.ReplacementStatus{ClaimName: "claim2", PreviousSpoke: "spoke2"}},
+			claimName: "claim1",
+			want:      "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ""
+			if recorded := recordedReplacement(&apiv1.ResilientCluster{Status: tt.status}, tt.claimName); recorded != nil {
+				got = recorded.PreviousSpoke
+			}
+			if got != tt.want {
+				t.Errorf("recordedReplacement() = '%s', want '%s'", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReconcileRecordedReplacement(t *testing.T) {
+	scheme := runtime.NewScheme()
+	for _, install := range []func(*runtime.Scheme) error{apiv1.Install, hivev1.AddToScheme, corev1.AddToScheme} {
+		if err := install(scheme); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// a first pass performed the actions and recorded the replacement, the OLD spoke is gone, but removing the
+	// annotation failed, leaving the claim annotated
+	claim := &hivev1.ClusterClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "pool-namespace",
+			Name:        "claim1",
+			Annotations: map[string]string{mcra.AnnotationPreviousSpoke: "spoke1"},
+			Labels:      map[string]string{mcra.LabelLogicalName: "cluster"},
+		},
+		Spec: hivev1.ClusterClaimSpec{ClusterPoolName: "pool", Namespace: "spoke2"},
+		Status: hivev1.ClusterClaimStatus{Conditions: []hivev1.ClusterClaimCondition{
+			{Type: hivev1.ClusterRunningCondition, Status: corev1.ConditionTrue},
+			{Type: hivev1.ClusterClaimPendingCondition, Status: corev1.ConditionFalse},
+		}},
+	}
+	replacement := apiv1.ReplacementStatus{PreviousSpoke: "spoke1", ClaimName: "claim1", Phase: apiv1.ReplacementValidating}
+	newRc := &apiv1.ResilientCluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "spoke2", Name: "spoke2"},
+		Status: apiv1.ResilientClusterStatus{
+			Replacement: &replacement,
+			Lineage:     []string{"spoke0", "spoke1"},
+			History:     []apiv1.ReplacementStatus{{PreviousSpoke: "spoke0", ClaimName: "claim0"}, replacement},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(claim, newRc).Build()
+
+	reconciler := &ClaimReconciler{
+		Client:   fakeClient,
+		Reader:   fakeClient,
+		Scheme:   scheme,
+		Recorder: record.NewFakeRecorder(10),
+	}
+
+	// the second pass is expected to only remove the annotation, it requires no configuration nor manager namespace
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: claim.Namespace, Name: claim.Name}}
+	if _, err := reconciler.Reconcile(context.TODO(), request); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	gotClaim := &hivev1.ClusterClaim{}
+	if err := fakeClient.Get(context.TODO(), request.NamespacedName, gotClaim); err != nil {
+		t.Fatal(err)
+	}
+	if _, found := gotClaim.GetAnnotations()[mcra.AnnotationPreviousSpoke]; found {
+		t.Errorf("Reconcile() kept the %s annotation", mcra.AnnotationPreviousSpoke)
+	}
+
+	gotRc := &apiv1.ResilientCluster{}
+	if err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "spoke2", Name: "spoke2"}, gotRc); err != nil {
+		t.Fatal(err)
+	}
+	if !equality.Semantic.DeepEqual(gotRc.Status, newRc.Status) {
+		t.Errorf("Reconcile() rewrote the recorded status to %+v, want %+v", gotRc.Status, newRc.Status)
+	}
+}
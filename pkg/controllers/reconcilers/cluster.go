@@ -6,28 +6,49 @@ package reconcilers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	hivev1 "github.com/openshift/hive/apis/hive/v1"
 	apiv1 "github.com/rhecosystemappeng/multicluster-resiliency-addon/api/v1"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/controllers/exporter"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/hooks"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/inventory"
 	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/mcra"
 	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/metrics"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/notify"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
 	"os"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// failoverDeferredInterval is the interval for retrying a failover deferred by the fleet-wide limits.
+	failoverDeferredInterval = time.Minute
+	// suspendedRequeueInterval is the interval for retrying a replacement of a suspended cluster.
+	suspendedRequeueInterval = time.Minute
 )
 
 // ClusterReconciler is a receiver representing the MultiCluster-Resiliency-Addon operator reconciler for
 // ResilientCluster CRs.
 type ClusterReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Reader   client.Reader
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
 	Options
 }
 
@@ -45,6 +66,7 @@ func (r *ClusterReconciler) setupWithManager(mgr ctrl.Manager) error {
 // +kubebuilder:rbac:groups=appeng.ecosystem.redhat.com,resources=resilientclusterclaimbinding,verbs=*
 // +kubebuilder:rbac:groups=appeng.ecosystem.redhat.com,resources=resilientclusterclaimbinding/finalizer,verbs=*
 // +kubebuilder:rbac:groups=addon.open-cluster-management.io,resources=addondeploymentconfigs,verbs=*
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;delete
 
 // Reconcile is watching ResilientCluster CRs, determining whether a new Spoke cluster is required, and handling
 // the cluster provisioning using OpenShift Hive API. Note, further permissions are listed in AddonReconciler.Reconcile
@@ -87,8 +109,47 @@ func (r *ClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		return ctrl.Result{}, nil
 	}
 
-	// decide whether a new ClusterClaim is required based on ResilientCluster status
-	if !requiresNewClaim(rc) {
+	// the suspend annotation is surfaced in the status, the update triggers another reconcile
+	if suspended := isSuspended(rc); suspended != rc.Status.Suspended {
+		if err := r.recordSuspension(ctx, rc, suspended); err != nil {
+			logger.Error(err, fmt.Sprintf("%s failed recording suspension", subject.String()))
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	// a deferred or refused failover is dropped if the cluster recovered in the meantime
+	_, deferred := rc.GetAnnotations()[mcra.AnnotationFailoverDeferred]
+	_, refused := rc.GetAnnotations()[mcra.AnnotationFailoverRefused]
+	if (deferred || refused) && rc.Status.CurrentStatus.Availability == apiv1.ClusterAvailable {
+		logger.Info("cluster recovered, dropping deferred or refused failover")
+		return ctrl.Result{}, r.patchAnnotations(ctx, rc, map[string]string{
+			mcra.AnnotationFailoverDeferred: "",
+			mcra.AnnotationFailoverRefused:  "",
+		})
+	}
+
+	// a refused failover is not retried until the cluster recovers
+	if refused {
+		logger.Info("failover refused until the cluster recovers")
+		return ctrl.Result{}, nil
+	}
+
+	// a failover required while suspended is deferred, it is retried once resumed
+	if rc.Status.Suspended {
+		if requiresNewClaim(rc) {
+			message := "failover deferred while the ResilientCluster is suspended"
+			if err := r.deferFailover(ctx, rc, logicalNameOf(rc), deferredSuspended, message); err != nil {
+				logger.Error(err, "failed marking failover as deferred")
+				return ctrl.Result{}, err
+			}
+		}
+		logger.Info("suspended, not acting")
+		return ctrl.Result{}, nil
+	}
+
+	// decide whether a new ClusterClaim is required based on ResilientCluster status, or a deferred failover
+	if !requiresNewClaim(rc) && !deferred {
 		logger.Info("no claim required")
 		return ctrl.Result{}, nil
 	}
@@ -106,6 +167,44 @@ func (r *ClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		return ctrl.Result{}, err
 	}
 
+	// the fleet-wide settings are only read from the manager namespace
+	managerConfig, err := loadManagerConfiguration(ctx, r.Client, r.ConfigMapName, managerNamespace)
+	if err != nil && !errors.IsNotFound(err) {
+		logger.Error(err, "unable to load manager configuration")
+		return ctrl.Result{}, err
+	}
+
+	logicalName := logicalNameOf(rc)
+	now := time.Now()
+
+	// a cluster becoming unavailable during a blackout period is never replaced automatically
+	if period := activeBlackoutPeriod(rc, config, managerConfig, now); period != nil {
+		logger.Info("refusing failover during blackout period", "period", period.Name)
+		// the refusal is recorded once, dropping a deferred failover
+		err = r.patchAnnotations(ctx, rc, map[string]string{
+			mcra.AnnotationFailoverDeferred: "",
+			mcra.AnnotationFailoverRefused:  refusedBlackoutPeriod,
+		})
+		if err != nil {
+			logger.Error(err, "failed marking failover as refused")
+			return ctrl.Result{}, err
+		}
+		metrics.FailoverRefused.WithLabelValues(req.Namespace, refusedBlackoutPeriod).Inc()
+		r.Recorder.Eventf(rc, corev1.EventTypeWarning, "FailoverRefused", "failover refused during blackout period %s", period.Name)
+		r.Exporter.Publish(ctx, exporter.TypeReplacementRefused, exporter.ClusterData{Spoke: req.Namespace, LogicalName: logicalName, Reason: refusedBlackoutPeriod})
+		return ctrl.Result{}, nil
+	}
+
+	// a planned maintenance defers the failover until the window ends, it is then dropped if the cluster recovered
+	if window, end := activeMaintenanceWindow(ctx, r.Client, req.Namespace, config, managerConfig, now); window != nil {
+		message := fmt.Sprintf("failover deferred by maintenance window %s until %s", window.Name, end.UTC().Format(time.RFC3339))
+		if err = r.deferFailover(ctx, rc, logicalName, deferredMaintenanceWindow, message); err != nil {
+			logger.Error(err, "failed marking failover as deferred")
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: end.Sub(now)}, nil
+	}
+
 	pool, err := r.loadClusterPool(ctx, config.HivePoolName, managerNamespace)
 	if err != nil {
 		logger.Error(err, "unable to load hive pool")
@@ -114,17 +213,120 @@ func (r *ClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 
 	if err = verifyPool(pool); err != nil {
 		logger.Error(err, "verify hive pool failed")
+		r.Recorder.Eventf(rc, corev1.EventTypeWarning, "PoolNotReady", "cluster pool %s is not ready for claims", pool.Name)
 		return ctrl.Result{Requeue: true}, err
 	}
 
+	// the logical cluster might already be in the process of being replaced
+	pendingClaims, err := findPendingClaims(ctx, r.Client, config.HivePoolName, logicalName)
+	if err != nil {
+		logger.Error(err, "failed looking up pending claims", "logical-name", logicalName)
+		return ctrl.Result{}, err
+	}
+	if len(pendingClaims) > 0 {
+		logger.Info("logical cluster replacement already in progress", "logical-name", logicalName, "claim", pendingClaims[0].Name)
+		return ctrl.Result{}, nil
+	}
+
+	// the fleet-wide limits defer the failover, it is retried until admitted or the cluster recovers
+	fleet, err := evaluateFleet(ctx, r.Client, r.Reader, managerConfig)
+	if err != nil {
+		logger.Error(err, "failed evaluating fleet status")
+		return ctrl.Result{}, err
+	}
+	if reason := fleet.deferReason(managerConfig); reason != "" {
+		logger.Info("fleet status", "in-flight", fleet.InFlight, "in-window", fleet.InWindow, "unavailable", fleet.Unavailable)
+		message := fmt.Sprintf("failover deferred by the %s", strings.ReplaceAll(reason, "_", " "))
+		if err = r.deferFailover(ctx, rc, logicalName, reason, message); err != nil {
+			logger.Error(err, "failed marking failover as deferred")
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: failoverDeferredInterval}, nil
+	}
+
+	// replacing a cluster with unreplicated volumes loses their data, the policy might defer it until the inventory
+	// reported by the agent shows them replicated
+	if config.UnreplicatedVolumes == unreplicatedVolumesRefuse {
+		unreplicated, err := r.unreplicatedClaims(ctx, rc.Namespace)
+		if err != nil {
+			logger.Error(err, "failed loading inventory")
+			return ctrl.Result{}, err
+		}
+		if len(unreplicated) > 0 {
+			logger.Info("unreplicated volumes", "claims", unreplicated)
+			message := fmt.Sprintf("failover deferred by the unreplicated volumes %v", unreplicated)
+			if err = r.deferFailover(ctx, rc, logicalName, deferredUnreplicated, message); err != nil {
+				logger.Error(err, "failed marking failover as deferred")
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{RequeueAfter: failoverDeferredInterval}, nil
+		}
+	}
+
+	// a failover vetoed by a hook is not retried until the availability changes again
+	if vetoedByHook(rc) {
+		logger.Info("failover vetoed by a pre-failover hook")
+		return ctrl.Result{}, nil
+	}
+
+	// invoke the pre-failover hooks, resuming from the results recorded on the ResilientCluster, hooks are only
+	// accepted from the manager configuration as their jobs run in the manager namespace
+	previousResults := preFailoverResults(rc)
+	hookResults, vetoed, pending := hooks.Run(ctx, r.Client, r.Reader, managerNamespace, managerConfig.Hooks, hooks.Payload{
+		Phase:       apiv1.HookPreFailover,
+		OldSpoke:    req.Namespace,
+		LogicalName: logicalName,
+		Reason:      replacementReason(rc),
+	}, previousResults)
+	if len(hookResults) > len(previousResults) {
+		err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			current := &apiv1.ResilientCluster{}
+			if err := r.Client.Get(ctx, subject, current); err != nil {
+				return err
+			}
+			current.Status.PreFailoverHooks = hookResults
+			return r.Client.Update(ctx, current)
+		})
+		if err != nil {
+			logger.Error(err, "failed recording pre-failover hooks")
+			return ctrl.Result{}, err
+		}
+	}
+	if vetoed {
+		metrics.FailoverRefused.WithLabelValues(req.Namespace, "hook_veto").Inc()
+		r.Recorder.Event(rc, corev1.EventTypeWarning, "FailoverRefused", "failover vetoed by a pre-failover hook")
+		r.Exporter.Publish(ctx, exporter.TypeReplacementRefused, exporter.ClusterData{Spoke: req.Namespace, LogicalName: logicalName, Reason: "hook_veto"})
+		return ctrl.Result{}, nil
+	}
+	// the decision is persisted while polling, so the failover is not dropped if the status changes in the meantime
+	if pending {
+		if err = r.deferFailover(ctx, rc, logicalName, deferredPendingHooks, "failover deferred by pending pre-failover hooks"); err != nil {
+			logger.Error(err, "failed marking failover as deferred")
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: hooks.PollInterval}, nil
+	}
+
+	claimAnnotations := map[string]string{
+		mcra.AnnotationCreatedBy:         mcra.AddonName,
+		mcra.AnnotationPreviousSpoke:     req.Namespace,
+		mcra.AnnotationReplacementReason: replacementReason(rc),
+	}
+	if len(hookResults) > 0 {
+		serialized, err := json.Marshal(hookResults)
+		if err != nil {
+			logger.Error(err, "failed serializing pre-failover hooks")
+			return ctrl.Result{}, err
+		}
+		claimAnnotations[mcra.AnnotationPreFailoverHooks] = string(serialized)
+	}
+
 	claimName := fmt.Sprintf("mcra-claim-%s", rand.String(4))
 	newClaim := &hivev1.ClusterClaim{}
 	newClaim.SetName(claimName)
 	newClaim.SetNamespace(config.HivePoolName)
-	newClaim.SetAnnotations(map[string]string{
-		mcra.AnnotationCreatedBy:     mcra.AddonName,
-		mcra.AnnotationPreviousSpoke: req.Namespace,
-	})
+	newClaim.SetLabels(map[string]string{mcra.LabelLogicalName: logicalName})
+	newClaim.SetAnnotations(claimAnnotations)
 	newClaim.Spec = hivev1.ClusterClaimSpec{ClusterPoolName: config.HivePoolName}
 
 	if err = r.Client.Create(ctx, newClaim); err != nil {
@@ -134,6 +336,22 @@ func (r *ClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 
 	// the namespace is the name of the old spoke
 	metrics.NewClusterClaimCreated.WithLabelValues(config.HivePoolName, claimName, req.Namespace).Inc()
+	r.Recorder.Eventf(rc, corev1.EventTypeNormal, "ClaimCreated", "created claim %s/%s for replacing the cluster", config.HivePoolName, claimName)
+	r.Recorder.Eventf(newClaim, corev1.EventTypeNormal, "ClaimCreated", "claim created for replacing cluster %s", req.Namespace)
+	r.Exporter.Publish(ctx, exporter.TypeReplacementStarted, exporter.ClusterData{Spoke: req.Namespace, LogicalName: logicalName, Reason: replacementReason(rc)})
+
+	if deferred {
+		if err = r.setFailoverDeferred(ctx, rc, ""); err != nil {
+			logger.Error(err, "failed clearing deferred failover")
+		}
+	}
+
+	notify.Send(ctx, r.Reader, managerNamespace, managerConfig.Notifiers, notify.Event{
+		Type:        notify.EventClaimCreated,
+		Spoke:       req.Namespace,
+		LogicalName: logicalName,
+		Message:     fmt.Sprintf("claim %s created from pool %s", claimName, config.HivePoolName),
+	})
 
 	return ctrl.Result{}, nil
 }
@@ -149,6 +367,118 @@ func (r *ClusterReconciler) loadClusterPool(ctx context.Context, poolName, manag
 	return pool, r.Client.Get(ctx, subject, pool)
 }
 
+// deferFailover is used for deferring the failover of a ResilientCluster for the reason. Deferring is counted,
+// recorded, and published once per reason.
+func (r *ClusterReconciler) deferFailover(ctx context.Context, rc *apiv1.ResilientCluster, logicalName, reason, message string) error {
+	if rc.GetAnnotations()[mcra.AnnotationFailoverDeferred] == reason {
+		return nil
+	}
+
+	log.FromContext(ctx).Info("deferring failover", "reason", reason)
+	metrics.FailoverRefused.WithLabelValues(rc.Namespace, reason).Inc()
+	r.Recorder.Event(rc, corev1.EventTypeWarning, "FailoverDeferred", message)
+	r.Exporter.Publish(ctx, exporter.TypeReplacementRefused, exporter.ClusterData{Spoke: rc.Namespace, LogicalName: logicalName, Reason: reason})
+	return r.setFailoverDeferred(ctx, rc, reason)
+}
+
+// recordSuspension is used for recording the ResilientCluster is suspended or resumed in its status.
+func (r *ClusterReconciler) recordSuspension(ctx context.Context, rc *apiv1.ResilientCluster, suspended bool) error {
+	rc.Status.Suspended = suspended
+	rc.Status.SuspendedTime = nil
+	if suspended {
+		now := metav1.Now()
+		rc.Status.SuspendedTime = &now
+	}
+	if err := r.Client.Update(ctx, rc); err != nil {
+		return err
+	}
+
+	if suspended {
+		r.Recorder.Event(rc, corev1.EventTypeNormal, "Suspended", "automation suspended for the cluster")
+	} else {
+		r.Recorder.Event(rc, corev1.EventTypeNormal, "Resumed", "automation resumed for the cluster")
+	}
+	return nil
+}
+
+// setFailoverDeferred is used for annotating the ResilientCluster with the reason its failover is deferred, an empty
+// reason removes the annotation.
+func (r *ClusterReconciler) setFailoverDeferred(ctx context.Context, rc *apiv1.ResilientCluster, reason string) error {
+	return r.patchAnnotations(ctx, rc, map[string]string{mcra.AnnotationFailoverDeferred: reason})
+}
+
+// patchAnnotations is used for patching the annotations of the ResilientCluster, an empty value removes the
+// annotation.
+func (r *ClusterReconciler) patchAnnotations(ctx context.Context, rc *apiv1.ResilientCluster, changes map[string]string) error {
+	rcPatch := client.MergeFrom(rc.DeepCopy())
+
+	annotations := rc.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	for key, value := range changes {
+		if value == "" {
+			delete(annotations, key)
+		} else {
+			annotations[key] = value
+		}
+	}
+	rc.SetAnnotations(annotations)
+
+	return r.Client.Patch(ctx, rc, rcPatch)
+}
+
+// unreplicatedClaims is used for listing the unreplicated PersistentVolumeClaims in the last inventory reported by
+// the Spoke's Agent. A Spoke with no reported inventory has no known PersistentVolumeClaims.
+func (r *ClusterReconciler) unreplicatedClaims(ctx context.Context, spokeName string) ([]string, error) {
+	subject := types.NamespacedName{
+		Namespace: spokeName,
+		Name:      mcra.InventoryConfigMapName,
+	}
+
+	configMap := &corev1.ConfigMap{}
+	if err := r.Client.Get(ctx, subject, configMap); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	inv, err := inventory.FromConfigMap(configMap)
+	if err != nil {
+		return nil, err
+	}
+	return inv.UnreplicatedClaims(), nil
+}
+
+// vetoedByHook returns true if a pre-failover hook vetoed replacing the cluster since the current availability status.
+func vetoedByHook(rc *apiv1.ResilientCluster) bool {
+	for _, result := range preFailoverResults(rc) {
+		if result.Vetoed {
+			return true
+		}
+	}
+	return false
+}
+
+// preFailoverResults returns the results of the pre-failover hooks invoked since the current availability status.
+func preFailoverResults(rc *apiv1.ResilientCluster) []apiv1.HookResult {
+	var results []apiv1.HookResult
+	for _, result := range rc.Status.PreFailoverHooks {
+		if !result.StartTime.Before(&rc.Status.CurrentStatus.Time) {
+			results = append(results, result)
+		}
+	}
+	return results
+}
+
+// isSuspended returns true if the ResilientCluster is annotated as suspended, freezing the Addon's automation for the
+// Spoke cluster until resumed.
+func isSuspended(rc *apiv1.ResilientCluster) bool {
+	suspended, _ := strconv.ParseBool(rc.GetAnnotations()[mcra.AnnotationSuspend])
+	return suspended
+}
+
 // requiresNewClaim takes an apiv1.ResilientCluster and determines whether a new cluster claim is required. i.e. If the
 // cluster is not available, a new claim is required. Currently, the decision is made based on the availability status,
 // for future steps we can make this more robust. For instance, check the time of the previous status change and only
@@ -158,6 +488,15 @@ func requiresNewClaim(rc *apiv1.ResilientCluster) bool {
 		rc.Status.PreviousStatus.Availability == apiv1.ClusterAvailable
 }
 
+// replacementReason is used for describing why a ResilientCluster requires a new cluster, for recording in the
+// replacement history.
+func replacementReason(rc *apiv1.ResilientCluster) string {
+	return fmt.Sprintf("cluster availability changed from %s to %s at %s",
+		rc.Status.PreviousStatus.Availability,
+		rc.Status.CurrentStatus.Availability,
+		rc.Status.CurrentStatus.Time.UTC().Format(time.RFC3339))
+}
+
 // verifyPool is used for verifying a hivev1.ClusterPool is ok and a ClusterClaim can be made. Initial implementation is
 // based on pool's ready status. Further verifications, i.e. checking condition statuses, can be added here.
 func verifyPool(pool *hivev1.ClusterPool) error {
@@ -170,6 +509,12 @@ func verifyPool(pool *hivev1.ClusterPool) error {
 // init is registering the ClusterReconciler setup function for execution.
 func init() {
 	reconcilerFuncs = append(reconcilerFuncs, func(mgr manager.Manager, options Options) error {
-		return (&ClusterReconciler{Client: mgr.GetClient(), Scheme: mgr.GetScheme(), Options: options}).setupWithManager(mgr)
+		return (&ClusterReconciler{
+			Client:   mgr.GetClient(),
+			Reader:   mgr.GetAPIReader(),
+			Scheme:   mgr.GetScheme(),
+			Recorder: mgr.GetEventRecorderFor("mcra-cluster-controller"),
+			Options:  options,
+		}).setupWithManager(mgr)
 	})
 }
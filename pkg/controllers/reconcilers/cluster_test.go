@@ -0,0 +1,149 @@
+// Copyright (c) 2023 Red Hat, Inc.
+
+package reconcilers
+
+import (
+	"context"
+	hivev1 "github.com/openshift/hive/apis/hive/v1"
+	apiv1 "github.com/rhecosystemappeng/multicluster-resiliency-addon/api/v1"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/hooks"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/mcra"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"testing"
+	"time"
+)
+
+func TestReconcilePendingHooksStatusChanged(t *testing.T) {
+	t.Setenv("POD_NAMESPACE", "manager")
+
+	scheme := runtime.NewScheme()
+	for _, install := range []func(*runtime.Scheme) error{
+		apiv1.Install, hivev1.AddToScheme, corev1.AddToScheme, batchv1.AddToScheme, clusterv1.AddToScheme} {
+		if err := install(scheme); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	config := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "manager", Name: "mcra-config"},
+		Data: map[string]string{
+			"hive_pool_name": "pool",
+			"hooks": `
+- name: drain
+  phase: PreFailover
+  job:
+    template:
+      spec:
+        restartPolicy: Never
+        containers:
+          - name: drain
+            image: drain`,
+		},
+	}
+	pool := &hivev1.ClusterPool{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "manager", Name: "pool"},
+		Status:     hivev1.ClusterPoolStatus{Size: 1},
+	}
+	becameUnavailable := metav1.NewTime(time.Now().Add(-time.Minute).Truncate(time.Second))
+	rc := &apiv1.ResilientCluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "spoke1", Name: "spoke1"},
+		Status: apiv1.ResilientClusterStatus{
+			PreviousStatus: apiv1.ClusterStatus{Availability: apiv1.ClusterAvailable},
+			CurrentStatus:  apiv1.ClusterStatus{Availability: apiv1.ClusterNotAvailable, Time: becameUnavailable},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(config, pool, rc).Build()
+
+	reconciler := &ClusterReconciler{
+		Client:   fakeClient,
+		Reader:   fakeClient,
+		Scheme:   scheme,
+		Recorder: record.NewFakeRecorder(100),
+		Options:  Options{ConfigMapName: "mcra-config"},
+	}
+	ctx := context.TODO()
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "spoke1", Name: "spoke1"}}
+
+	reconcile := func() ctrl.Result {
+		result, err := reconciler.Reconcile(ctx, request)
+		if err != nil {
+			t.Fatalf("Reconcile() error = %v", err)
+		}
+		return result
+	}
+	fetchRc := func() *apiv1.ResilientCluster {
+		current := &apiv1.ResilientCluster{}
+		if err := fakeClient.Get(ctx, request.NamespacedName, current); err != nil {
+			t.Fatal(err)
+		}
+		return current
+	}
+	countClaims := func() int {
+		claims := &hivev1.ClusterClaimList{}
+		if err := fakeClient.List(ctx, claims, client.InNamespace("pool")); err != nil {
+			t.Fatal(err)
+		}
+		return len(claims.Items)
+	}
+
+	// the first poll creates the hook job and defers the failover
+	if result := reconcile(); result.RequeueAfter != hooks.PollInterval {
+		t.Fatalf("first poll Reconcile() = %+v, want requeue after %s", result, hooks.PollInterval)
+	}
+	if reason := fetchRc().GetAnnotations()[mcra.AnnotationFailoverDeferred]; reason != deferredPendingHooks {
+		t.Fatalf("first poll deferred reason = '%s', want '%s'", reason, deferredPendingHooks)
+	}
+
+	// the status changes between polls, the cluster is still not available, but no longer requires a new claim
+	changed := fetchRc()
+	changed.Status.PreviousStatus = changed.Status.CurrentStatus
+	changed.Status.CurrentStatus = apiv1.ClusterStatus{Availability: apiv1.ClusterNotAvailable, Time: metav1.NewTime(becameUnavailable.Add(30 * time.Second))}
+	if err := fakeClient.Update(ctx, changed); err != nil {
+		t.Fatal(err)
+	}
+	if requiresNewClaim(changed) {
+		t.Fatal("the changed status is expected not to require a new claim by itself")
+	}
+
+	// the second poll keeps waiting for the hooks
+	if result := reconcile(); result.RequeueAfter != hooks.PollInterval {
+		t.Fatalf("second poll Reconcile() = %+v, want requeue after %s", result, hooks.PollInterval)
+	}
+	if claims := countClaims(); claims != 0 {
+		t.Fatalf("second poll created %d claims, want none before the hooks finished", claims)
+	}
+
+	// the hooks finish, the next poll claims a new cluster and clears the deferral
+	jobs := &batchv1.JobList{}
+	if err := fakeClient.List(ctx, jobs, client.InNamespace("manager")); err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs.Items) == 0 {
+		t.Fatal("no hook job created")
+	}
+	for _, job := range jobs.Items {
+		job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}
+		if err := fakeClient.Update(ctx, &job); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if result := reconcile(); result.RequeueAfter != 0 {
+		t.Fatalf("final poll Reconcile() = %+v, want no requeue", result)
+	}
+	if claims := countClaims(); claims != 1 {
+		t.Fatalf("final poll created %d claims, want 1", claims)
+	}
+	if _, found := fetchRc().GetAnnotations()[mcra.AnnotationFailoverDeferred]; found {
+		t.Errorf("final poll kept the %s annotation", mcra.AnnotationFailoverDeferred)
+	}
+}
@@ -4,16 +4,57 @@ package reconcilers
 
 import (
 	"context"
+	"fmt"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/controllers/actions"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/hooks"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/maintenance"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/notify"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/yaml"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // This file contains utility functions for loading the configuration for use with the various controllers.
 
+// policies for replacing clusters with unreplicated PersistentVolumeClaims
+const (
+	unreplicatedVolumesWarn   = "warn"
+	unreplicatedVolumesRefuse = "refuse"
+)
+
 type Config struct {
-	HivePoolName string
+	HivePoolName               string
+	AddonsAllowList            []string
+	AddonsDenyList             []string
+	MigrationRules             []actions.MigrationRule
+	SecretsSelector            string
+	SecretsNames               []string
+	ManagedClusterInclude      []string
+	ManagedClusterExclude      []string
+	BackupTarget               string
+	BackupDirectory            string
+	BackupS3Endpoint           string
+	BackupS3Bucket             string
+	BackupS3Region             string
+	BackupS3Secret             string
+	BackupSecrets              bool
+	ClusterDeploymentRetention time.Duration
+	UnreplicatedVolumes        string
+	Hooks                      []hooks.Hook
+	SmokeChecks                []actions.SmokeCheck
+	ValidationTimeout          time.Duration
+	Notifiers                  []notify.Notifier
+	MaxConcurrentFailovers     int
+	MaxFailoversPerWindow      int
+	FailoverWindow             time.Duration
+	CircuitBreakerThreshold    int
+	MaintenanceWindows         []maintenance.Window
+	BlackoutPeriods            []maintenance.Window
 }
 
 // loadConfiguration will first attempt to load the configmap from the cluster-namespace, if failed, will load the one
@@ -30,7 +71,7 @@ func loadConfiguration(ctx context.Context, c client.Client, configName, cluster
 	// return configmap from cluster namespace if available
 	if err := c.Get(ctx, subject, cmap); err == nil {
 		logger.Info("using config from cluster namespace")
-		return configMapToConfig(cmap), nil
+		return configMapToConfig(cmap)
 	}
 
 	logger.Info("using config from manager namespace")
@@ -40,15 +81,167 @@ func loadConfiguration(ctx context.Context, c client.Client, configName, cluster
 		return Config{}, err
 	}
 
-	return configMapToConfig(cmap), nil
+	return configMapToConfig(cmap)
 }
 
 // configMapToConfig is used for extracting known keys from a ConfigMap and build a new Config from the extracted values.
-func configMapToConfig(configMap *corev1.ConfigMap) Config {
+func configMapToConfig(configMap *corev1.ConfigMap) (Config, error) {
 	config := Config{}
 	if poolName, found := configMap.Data["hive_pool_name"]; found {
 		config.HivePoolName = poolName
 	}
+	if allowList, found := configMap.Data["addons_allow_list"]; found {
+		config.AddonsAllowList = splitList(allowList)
+	}
+	if denyList, found := configMap.Data["addons_deny_list"]; found {
+		config.AddonsDenyList = splitList(denyList)
+	}
+	if selector, found := configMap.Data["secrets_label_selector"]; found {
+		config.SecretsSelector = strings.TrimSpace(selector)
+	}
+	if names, found := configMap.Data["secrets_names"]; found {
+		config.SecretsNames = splitList(names)
+	}
+	if include, found := configMap.Data["managed_cluster_include"]; found {
+		config.ManagedClusterInclude = splitList(include)
+	}
+	if exclude, found := configMap.Data["managed_cluster_exclude"]; found {
+		config.ManagedClusterExclude = splitList(exclude)
+	}
+	if target, found := configMap.Data["backup_target"]; found {
+		config.BackupTarget = strings.TrimSpace(target)
+	}
+	if directory, found := configMap.Data["backup_directory"]; found {
+		config.BackupDirectory = strings.TrimSpace(directory)
+	}
+	if endpoint, found := configMap.Data["backup_s3_endpoint"]; found {
+		config.BackupS3Endpoint = strings.TrimSpace(endpoint)
+	}
+	if bucket, found := configMap.Data["backup_s3_bucket"]; found {
+		config.BackupS3Bucket = strings.TrimSpace(bucket)
+	}
+	if region, found := configMap.Data["backup_s3_region"]; found {
+		config.BackupS3Region = strings.TrimSpace(region)
+	}
+	if secret, found := configMap.Data["backup_s3_secret"]; found {
+		config.BackupS3Secret = strings.TrimSpace(secret)
+	}
+	if includeSecrets, found := configMap.Data["backup_include_secrets"]; found {
+		parsed, err := strconv.ParseBool(strings.TrimSpace(includeSecrets))
+		if err != nil {
+			return Config{}, fmt.Errorf("failed parsing backup_include_secrets, %v", err)
+		}
+		config.BackupSecrets = parsed
+	}
+	if retention, found := configMap.Data["cluster_deployment_retention"]; found {
+		parsed, err := time.ParseDuration(strings.TrimSpace(retention))
+		if err != nil {
+			return Config{}, fmt.Errorf("failed parsing cluster_deployment_retention, %v", err)
+		}
+		config.ClusterDeploymentRetention = parsed
+	}
+	if policy, found := configMap.Data["unreplicated_volumes"]; found {
+		config.UnreplicatedVolumes = strings.TrimSpace(policy)
+		if config.UnreplicatedVolumes != unreplicatedVolumesWarn && config.UnreplicatedVolumes != unreplicatedVolumesRefuse {
+			return Config{}, fmt.Errorf("unknown unreplicated_volumes policy %s", config.UnreplicatedVolumes)
+		}
+	}
+	if hookList, found := configMap.Data["hooks"]; found {
+		if err := yaml.Unmarshal([]byte(hookList), &config.Hooks); err != nil {
+			return Config{}, fmt.Errorf("failed parsing hooks, %v", err)
+		}
+		for _, hook := range config.Hooks {
+			if err := hook.Validate(); err != nil {
+				return Config{}, err
+			}
+		}
+	}
+	if checks, found := configMap.Data["smoke_checks"]; found {
+		if err := yaml.Unmarshal([]byte(checks), &config.SmokeChecks); err != nil {
+			return Config{}, fmt.Errorf("failed parsing smoke_checks, %v", err)
+		}
+	}
+	if timeout, found := configMap.Data["validation_timeout"]; found {
+		parsed, err := time.ParseDuration(strings.TrimSpace(timeout))
+		if err != nil {
+			return Config{}, fmt.Errorf("failed parsing validation_timeout, %v", err)
+		}
+		config.ValidationTimeout = parsed
+	}
+	if notifiers, found := configMap.Data["notifiers"]; found {
+		if err := yaml.Unmarshal([]byte(notifiers), &config.Notifiers); err != nil {
+			return Config{}, fmt.Errorf("failed parsing notifiers, %v", err)
+		}
+		for _, notifier := range config.Notifiers {
+			if err := notifier.Validate(); err != nil {
+				return Config{}, err
+			}
+		}
+	}
+	if maxConcurrent, found := configMap.Data["max_concurrent_failovers"]; found {
+		parsed, err := strconv.Atoi(strings.TrimSpace(maxConcurrent))
+		if err != nil {
+			return Config{}, fmt.Errorf("failed parsing max_concurrent_failovers, %v", err)
+		}
+		config.MaxConcurrentFailovers = parsed
+	}
+	if maxPerWindow, found := configMap.Data["max_failovers_per_window"]; found {
+		parsed, err := strconv.Atoi(strings.TrimSpace(maxPerWindow))
+		if err != nil {
+			return Config{}, fmt.Errorf("failed parsing max_failovers_per_window, %v", err)
+		}
+		config.MaxFailoversPerWindow = parsed
+	}
+	if window, found := configMap.Data["failover_window"]; found {
+		parsed, err := time.ParseDuration(strings.TrimSpace(window))
+		if err != nil {
+			return Config{}, fmt.Errorf("failed parsing failover_window, %v", err)
+		}
+		config.FailoverWindow = parsed
+	}
+	if threshold, found := configMap.Data["circuit_breaker_threshold"]; found {
+		parsed, err := strconv.Atoi(strings.TrimSpace(threshold))
+		if err != nil || parsed < 0 || parsed > 100 {
+			return Config{}, fmt.Errorf("circuit_breaker_threshold must be a percentage, got %s", threshold)
+		}
+		config.CircuitBreakerThreshold = parsed
+	}
+	if windows, found := configMap.Data["maintenance_windows"]; found {
+		if err := yaml.Unmarshal([]byte(windows), &config.MaintenanceWindows); err != nil {
+			return Config{}, fmt.Errorf("failed parsing maintenance_windows, %v", err)
+		}
+		for _, window := range config.MaintenanceWindows {
+			if err := window.Validate(); err != nil {
+				return Config{}, err
+			}
+		}
+	}
+	if periods, found := configMap.Data["blackout_periods"]; found {
+		if err := yaml.Unmarshal([]byte(periods), &config.BlackoutPeriods); err != nil {
+			return Config{}, fmt.Errorf("failed parsing blackout_periods, %v", err)
+		}
+		for _, period := range config.BlackoutPeriods {
+			if err := period.Validate(); err != nil {
+				return Config{}, err
+			}
+		}
+	}
+	if rules, found := configMap.Data["migration_rules"]; found {
+		if err := yaml.Unmarshal([]byte(rules), &config.MigrationRules); err != nil {
+			return Config{}, fmt.Errorf("failed parsing migration_rules, %v", err)
+		}
+	}
 
-	return config
+	return config, nil
+}
+
+// splitList is used for splitting a comma-separated list value into a slice of trimmed non-empty members.
+func splitList(value string) []string {
+	var members []string
+	for _, member := range strings.Split(value, ",") {
+		if trimmed := strings.TrimSpace(member); trimmed != "" {
+			members = append(members, trimmed)
+		}
+	}
+	return members
 }
@@ -0,0 +1,254 @@
+// Copyright (c) 2023 Red Hat, Inc.
+
+package reconcilers
+
+// This file hosts the FleetReconciler implementation and the fleet-wide failover limits.
+
+import (
+	"context"
+	"fmt"
+	hivev1 "github.com/openshift/hive/apis/hive/v1"
+	apiv1 "github.com/rhecosystemappeng/multicluster-resiliency-addon/api/v1"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/mcra"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/metrics"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"os"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"strconv"
+	"time"
+)
+
+const (
+	// defaultFailoverWindow is the window for the failovers rate limit if not configured.
+	defaultFailoverWindow = time.Hour
+	// fleetRequeueInterval is the interval for refreshing the fleet status, the failovers leave the window over time.
+	fleetRequeueInterval = time.Minute
+	// inFlightTimeout is the time a ClusterClaim not yet done replacing is counted as in-flight, a replacement failing
+	// before it is done does not hold a concurrent failover forever.
+	inFlightTimeout = 2 * time.Hour
+)
+
+// fleetRequest is the single request the fleet status is evaluated for, all the watched changes are mapped to it.
+var fleetRequest = reconcile.Request{NamespacedName: types.NamespacedName{Name: "fleet"}}
+
+// reasons for deferring a failover, see deferFailover
+const (
+	deferredCircuitBreaker    = "circuit_breaker"
+	deferredConcurrencyLimit  = "concurrency_limit"
+	deferredRateLimit         = "rate_limit"
+	deferredMaintenanceWindow = "maintenance_window"
+	deferredSuspended         = "suspended"
+	deferredUnreplicated      = "unreplicated_volumes"
+	deferredPendingHooks      = "pending_hooks"
+)
+
+// reasons for refusing a failover, a refused failover is not retried until the cluster recovers
+const (
+	refusedBlackoutPeriod = "blackout_period"
+)
+
+// fleetStatus describes the failovers across all the ResilientClusters.
+type fleetStatus struct {
+	Resilient, Unavailable int
+	InFlight, InWindow     int
+	CircuitOpen            bool
+}
+
+// FleetReconciler is a receiver representing the MultiCluster-Resiliency-Addon operator reconciler for reporting the
+// fleet status.
+type FleetReconciler struct {
+	client.Client
+	Reader client.Reader
+	Scheme *runtime.Scheme
+	Options
+}
+
+// setupWithManager is used for setting up the controller named 'mcra-fleet-controller' with the manager. The changes
+// of all the ResilientCluster and ClusterClaim CRs are mapped to the single fleetRequest, evaluating the fleet once
+// per change, and once every fleetRequeueInterval.
+func (r *FleetReconciler) setupWithManager(mgr ctrl.Manager) error {
+	toFleet := handler.EnqueueRequestsFromMapFunc(func(context.Context, client.Object) []reconcile.Request {
+		return []reconcile.Request{fleetRequest}
+	})
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("mcra-fleet-controller").
+		Watches(&apiv1.ResilientCluster{}, toFleet).
+		Watches(&hivev1.ClusterClaim{}, toFleet).
+		Complete(r)
+}
+
+// +kubebuilder:rbac:groups=appeng.ecosystem.redhat.com,resources=resilientclusters,verbs=get;list;watch
+// +kubebuilder:rbac:groups=hive.openshift.io,resources=clusterclaims,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update
+
+// Reconcile is watching ResilientCluster and ClusterClaim CRs, evaluating the fleet status on every change, and
+// reporting it in the status ConfigMap in the manager namespace.
+func (r *FleetReconciler) Reconcile(ctx context.Context, _ ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	managerNamespace, exist := os.LookupEnv("POD_NAMESPACE")
+	if !exist {
+		return ctrl.Result{}, fmt.Errorf("unable to load manager namespace from POD_NAMESPACE")
+	}
+
+	config, err := loadManagerConfiguration(ctx, r.Client, r.ConfigMapName, managerNamespace)
+	if err != nil && !errors.IsNotFound(err) {
+		logger.Error(err, "unable to load configuration")
+		return ctrl.Result{}, err
+	}
+
+	status, err := evaluateFleet(ctx, r.Client, r.Reader, config)
+	if err != nil {
+		logger.Error(err, "failed evaluating fleet status")
+		return ctrl.Result{}, err
+	}
+
+	if err = r.reportStatus(ctx, managerNamespace, status); err != nil {
+		logger.Error(err, "failed reporting fleet status")
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{RequeueAfter: fleetRequeueInterval}, nil
+}
+
+// reportStatus is used for writing the fleet status to the status ConfigMap, and setting the fleet metrics.
+func (r *FleetReconciler) reportStatus(ctx context.Context, namespace string, status fleetStatus) error {
+	breaker := "Closed"
+	metrics.FailoverCircuitBreakerOpen.Set(0)
+	if status.CircuitOpen {
+		breaker = "Open"
+		metrics.FailoverCircuitBreakerOpen.Set(1)
+	}
+	metrics.FailoversInFlight.Set(float64(status.InFlight))
+
+	data := map[string]string{
+		"circuit_breaker":      breaker,
+		"resilient_clusters":   strconv.Itoa(status.Resilient),
+		"unavailable_clusters": strconv.Itoa(status.Unavailable),
+		"in_flight_failovers":  strconv.Itoa(status.InFlight),
+		"failovers_in_window":  strconv.Itoa(status.InWindow),
+	}
+
+	statusMap := &corev1.ConfigMap{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: mcra.StatusConfigMapName}, statusMap); err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+
+		statusMap.SetName(mcra.StatusConfigMapName)
+		statusMap.SetNamespace(namespace)
+		statusMap.Data = data
+		return r.Client.Create(ctx, statusMap)
+	}
+
+	// the status is only updated when changed, not triggering needless events
+	modified := false
+	if statusMap.Data == nil {
+		statusMap.Data = map[string]string{}
+	}
+	for key, value := range data {
+		if statusMap.Data[key] != value {
+			statusMap.Data[key] = value
+			modified = true
+		}
+	}
+	if !modified {
+		return nil
+	}
+	return r.Client.Update(ctx, statusMap)
+}
+
+// evaluateFleet is used for counting the available and unavailable ResilientClusters, and the in-flight and recent
+// failovers. In-flight failovers are our ClusterClaims not yet done replacing, recent ones are our ClusterClaims created
+// within the failover window. The ClusterClaims are listed through the reader, counting the ones just created.
+func evaluateFleet(ctx context.Context, c client.Reader, reader client.Reader, config Config) (fleetStatus, error) {
+	status := fleetStatus{}
+
+	rcs := &apiv1.ResilientClusterList{}
+	if err := c.List(ctx, rcs); err != nil {
+		return status, err
+	}
+	for _, rc := range rcs.Items {
+		status.Resilient++
+		if rc.Status.CurrentStatus.Availability != apiv1.ClusterAvailable {
+			status.Unavailable++
+		}
+	}
+
+	window := config.FailoverWindow
+	if window == 0 {
+		window = defaultFailoverWindow
+	}
+
+	claims := &hivev1.ClusterClaimList{}
+	if err := reader.List(ctx, claims); err != nil {
+		return status, err
+	}
+	now := time.Now()
+	for i := range claims.Items {
+		claim := &claims.Items[i]
+		if claim.GetAnnotations()[mcra.AnnotationCreatedBy] != mcra.AddonName {
+			continue
+		}
+		if isInFlight(claim, now) {
+			status.InFlight++
+		}
+		if now.Sub(claim.CreationTimestamp.Time) < window {
+			status.InWindow++
+		}
+	}
+
+	status.CircuitOpen = config.CircuitBreakerThreshold > 0 && status.Resilient > 0 &&
+		status.Unavailable*100 > config.CircuitBreakerThreshold*status.Resilient
+
+	return status, nil
+}
+
+// isInFlight returns true if the ClusterClaim is not yet done replacing, and was created within the inFlightTimeout.
+func isInFlight(claim *hivev1.ClusterClaim, now time.Time) bool {
+	_, found := claim.GetAnnotations()[mcra.AnnotationPreviousSpoke]
+	return found && now.Sub(claim.CreationTimestamp.Time) < inFlightTimeout
+}
+
+// deferReason returns the reason for deferring another failover, or an empty string if not deferred.
+func (s fleetStatus) deferReason(config Config) string {
+	switch {
+	case s.CircuitOpen:
+		return deferredCircuitBreaker
+	case config.MaxConcurrentFailovers > 0 && s.InFlight >= config.MaxConcurrentFailovers:
+		return deferredConcurrencyLimit
+	case config.MaxFailoversPerWindow > 0 && s.InWindow >= config.MaxFailoversPerWindow:
+		return deferredRateLimit
+	}
+	return ""
+}
+
+// loadManagerConfiguration is used for loading the configuration from the manager namespace only, for the fleet-wide
+// settings.
+func loadManagerConfiguration(ctx context.Context, c client.Client, configName, managerNamespace string) (Config, error) {
+	cmap := &corev1.ConfigMap{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: managerNamespace, Name: configName}, cmap); err != nil {
+		return Config{}, err
+	}
+	return configMapToConfig(cmap)
+}
+
+// init is registering the FleetReconciler setup function for execution.
+func init() {
+	reconcilerFuncs = append(reconcilerFuncs, func(mgr manager.Manager, options Options) error {
+		return (&FleetReconciler{
+			Client:  mgr.GetClient(),
+			Reader:  mgr.GetAPIReader(),
+			Scheme:  mgr.GetScheme(),
+			Options: options,
+		}).setupWithManager(mgr)
+	})
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2023 Red Hat, Inc.
+
+package reconcilers
+
+import (
+	hivev1 "github.com/openshift/hive/apis/hive/v1"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/mcra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+	"time"
+)
+
+func TestDeferReason(t *testing.T) {
+	tests := []struct {
+		name   string
+		status fleetStatus
+		config Config
+		want   string
+	}{
+		{name: "no limits", status: fleetStatus{InFlight: 10, InWindow: 10}, want: ""},
+		{name: "circuit open", status: fleetStatus{CircuitOpen: true}, want: deferredCircuitBreaker},
+		{
+			name:   "circuit open wins over the limits",
+			status: fleetStatus{CircuitOpen: true, InFlight: 2, InWindow: 5},
+			config: Config{MaxConcurrentFailovers: 2, MaxFailoversPerWindow: 5},
+			want:   deferredCircuitBreaker,
+		},
+		{name: "below the concurrency limit", status: fleetStatus{InFlight: 1}, config: Config{MaxConcurrentFailovers: 2}, want: ""},
+		{name: "at the concurrency limit", status: fleetStatus{InFlight: 2}, config: Config{MaxConcurrentFailovers: 2}, want: deferredConcurrencyLimit},
+		{name: "below the rate limit", status: fleetStatus{InWindow: 4}, config: Config{MaxFailoversPerWindow: 5}, want: ""},
+		{name: "at the rate limit", status: fleetStatus{InWindow: 5}, config: Config{MaxFailoversPerWindow: 5}, want: deferredRateLimit},
+		{
+			name:   "concurrency limit wins over the rate limit",
+			status: fleetStatus{InFlight: 2, InWindow: 5},
+			config: Config{MaxConcurrentFailovers: 2, MaxFailoversPerWindow: 5},
+			want:   deferredConcurrencyLimit,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.status.deferReason(tt.config); got != tt.want {
+				t.Errorf("deferReason() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsInFlight(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		age         time.Duration
+		want        bool
+	}{
+		{name: "replacing", annotations: map[string]string{mcra.AnnotationPreviousSpoke: "spoke1"}, age: time.Minute, want: true},
+		{name: "done replacing", annotations: map[string]string{}, age: time.Minute, want: false},
+		{name: "timed out replacing", annotations: map[string]string{mcra.AnnotationPreviousSpoke: "spoke1"}, age: inFlightTimeout, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claim := &hivev1.ClusterClaim{ObjectMeta: metav1.ObjectMeta{
+				Annotations:       tt.annotations,
+				CreationTimestamp: metav1.NewTime(now.Add(-tt.age)),
+			}}
+			if got := isInFlight(claim, now); got != tt.want {
+				t.Errorf("isInFlight() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2023 Red Hat, Inc.
+
+package reconcilers
+
+// This file contains utility functions for resolving and looking up clusters by their logical name.
+
+import (
+	"context"
+	hivev1 "github.com/openshift/hive/apis/hive/v1"
+	apiv1 "github.com/rhecosystemappeng/multicluster-resiliency-addon/api/v1"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/mcra"
+	"k8s.io/apimachinery/pkg/types"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// logicalNameOf is used for resolving the logical name of a ResilientCluster. First generation clusters, which never
+// replaced another cluster, are logically named after their own cluster-namespace.
+func logicalNameOf(rc *apiv1.ResilientCluster) string {
+	if rc.Status.LogicalName != "" {
+		return rc.Status.LogicalName
+	}
+	if logicalName, found := rc.GetLabels()[mcra.LabelLogicalName]; found {
+		return logicalName
+	}
+	return rc.Namespace
+}
+
+// setLogicalName is used for setting the logical name on a ResilientCluster, both as a status field and as a label
+// for selecting ResilientClusters by their logical name.
+func setLogicalName(rc *apiv1.ResilientCluster, logicalName string) {
+	rc.Status.LogicalName = logicalName
+	rcLabels := rc.GetLabels()
+	if rcLabels == nil {
+		rcLabels = map[string]string{}
+	}
+	rcLabels[mcra.LabelLogicalName] = logicalName
+	rc.SetLabels(rcLabels)
+}
+
+// labelManagedCluster is used for setting the logical-name label on the ManagedCluster currently backing the logical
+// cluster, if not already set.
+func labelManagedCluster(ctx context.Context, c client.Client, clusterName, logicalName string) error {
+	mc := &clusterv1.ManagedCluster{}
+	if err := c.Get(ctx, types.NamespacedName{Name: clusterName}, mc); err != nil {
+		return err
+	}
+
+	if _, found := mc.GetLabels()[mcra.LabelLogicalName]; found {
+		return nil
+	}
+
+	mcPatch := client.MergeFrom(mc.DeepCopy())
+	mcLabels := mc.GetLabels()
+	if mcLabels == nil {
+		mcLabels = map[string]string{}
+	}
+	mcLabels[mcra.LabelLogicalName] = logicalName
+	mc.SetLabels(mcLabels)
+	return c.Patch(ctx, mc, mcPatch)
+}
+
+// findPendingClaims is used for listing the ClusterClaims created by us for replacing a logical cluster, that were
+// not yet handled by the ClaimReconciler.
+func findPendingClaims(ctx context.Context, c client.Client, poolNamespace, logicalName string) ([]hivev1.ClusterClaim, error) {
+	claims := &hivev1.ClusterClaimList{}
+	if err := c.List(ctx, claims, client.InNamespace(poolNamespace), client.MatchingLabels{mcra.LabelLogicalName: logicalName}); err != nil {
+		return nil, err
+	}
+
+	var pending []hivev1.ClusterClaim
+	for _, claim := range claims.Items {
+		if _, found := claim.GetAnnotations()[mcra.AnnotationPreviousSpoke]; found {
+			pending = append(pending, claim)
+		}
+	}
+	return pending, nil
+}
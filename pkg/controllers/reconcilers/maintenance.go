@@ -0,0 +1,51 @@
+// Copyright (c) 2023 Red Hat, Inc.
+
+package reconcilers
+
+// This file contains utility functions for evaluating maintenance windows and blackout periods suppressing failovers.
+
+import (
+	"context"
+	apiv1 "github.com/rhecosystemappeng/multicluster-resiliency-addon/api/v1"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/maintenance"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"time"
+)
+
+// activeMaintenanceWindow is used for finding a maintenance window active at the time for the Spoke. The windows are
+// the fleet-wide ones from the manager configuration, the ones from the Spoke's configuration, and the one annotated
+// on the Spoke's ManagedCluster. Returns nil if none is active. An invalid annotated window is logged and ignored.
+func activeMaintenanceWindow(ctx context.Context, c client.Client, spokeName string, config, managerConfig Config, now time.Time) (*maintenance.Window, time.Time) {
+	logger := log.FromContext(ctx)
+
+	windows := append(append([]maintenance.Window{}, managerConfig.MaintenanceWindows...), config.MaintenanceWindows...)
+
+	mc := &clusterv1.ManagedCluster{}
+	if err := c.Get(ctx, types.NamespacedName{Name: spokeName}, mc); err != nil {
+		if !errors.IsNotFound(err) {
+			logger.Error(err, "failed fetching ManagedCluster for maintenance window", "spoke", spokeName)
+		}
+	} else if window, err := maintenance.FromAnnotations(spokeName, mc.GetAnnotations()); err != nil {
+		logger.Error(err, "ignoring invalid maintenance window annotations", "spoke", spokeName)
+	} else if window != nil {
+		windows = append(windows, *window)
+	}
+
+	return maintenance.Active(windows, now)
+}
+
+// activeBlackoutPeriod is used for finding a blackout period, either fleet-wide or from the Spoke's configuration,
+// active when the ResilientCluster became unavailable or at the time. Returns nil if none is active.
+func activeBlackoutPeriod(rc *apiv1.ResilientCluster, config, managerConfig Config, now time.Time) *maintenance.Window {
+	periods := append(append([]maintenance.Window{}, managerConfig.BlackoutPeriods...), config.BlackoutPeriods...)
+
+	if period, _ := maintenance.Active(periods, rc.Status.CurrentStatus.Time.Time); period != nil {
+		return period
+	}
+	period, _ := maintenance.Active(periods, now)
+	return period
+}
@@ -42,6 +42,15 @@ func ownerName(name string) func(obj client.Object) bool {
 	}
 }
 
+// hasLabel is a utility function that takes a label and returns a function that takes a client.Object and returns
+// true if it contains the aforementioned label.
+func hasLabel(label string) func(obj client.Object) bool {
+	return func(obj client.Object) bool {
+		_, found := obj.GetLabels()[label]
+		return found
+	}
+}
+
 // hasAnnotation is a utility function that takes an annotation and returns a function that takes a client.Object and
 // returns true if it contains the aforementioned annotation. Use it with verifyObject.
 func hasAnnotation(annotation string) func(obj client.Object) bool {
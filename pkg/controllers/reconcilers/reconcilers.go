@@ -4,10 +4,15 @@ package reconcilers
 
 // This file contains options and functions for loading all the registered reconciler processes.
 
-import "sigs.k8s.io/controller-runtime/pkg/manager"
+import (
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/controllers/exporter"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
 
 type Options struct {
 	ConfigMapName string
+	// Exporter is used for publishing the lifecycle events, nil if not configured.
+	Exporter *exporter.Exporter
 }
 
 // reconcilerFuncs is used for registering reconciler funcs for setup.
@@ -0,0 +1,169 @@
+// Copyright (c) 2023 Red Hat, Inc.
+
+package reconcilers
+
+// This file hosts the RestoreReconciler implementation registering for OCM's ManifestWork CRs restoring workloads.
+
+import (
+	"context"
+	"fmt"
+	apiv1 "github.com/rhecosystemappeng/multicluster-resiliency-addon/api/v1"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/controllers/actions"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/mcra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	workv1 "open-cluster-management.io/api/work/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"time"
+)
+
+const (
+	// restoreRequeueInterval is the interval for checking whether the replacement was recorded on the ResilientCluster.
+	restoreRequeueInterval = 30 * time.Second
+	// restoreRecordTimeout is the time after which a restore ManifestWork is no longer expected to be recorded.
+	restoreRecordTimeout = time.Hour
+)
+
+// RestoreReconciler is a receiver representing the MultiCluster-Resiliency-Addon operator reconciler for ManifestWork
+// CRs restoring the previous Spoke's workload on its replacement.
+type RestoreReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	Options
+}
+
+// setupWithManager is used for setting up the controller named 'mcra-restore-controller' with the manager.
+// It uses predicates as event filters for verifying only handling ManifestWork CRs created by us for restoring.
+func (r *RestoreReconciler) setupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("mcra-restore-controller").
+		For(&workv1.ManifestWork{}).
+		WithEventFilter(predicate.NewPredicateFuncs(hasLabel(mcra.LabelRestoreFrom))).
+		Complete(r)
+}
+
+// +kubebuilder:rbac:groups=work.open-cluster-management.io,resources=manifestworks,verbs=get;list;watch
+
+// Reconcile is watching the restore ManifestWork CRs, recording the Velero Restore phase reported back by the
+// ManifestWork on the replacement Spoke's ResilientCluster.
+func (r *RestoreReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	workSubject := types.NamespacedName{
+		Namespace: req.Namespace,
+		Name:      req.Name,
+	}
+
+	// fetch the ManifestWork cr, end loop if not found
+	work := &workv1.ManifestWork{}
+	if err := r.Client.Get(ctx, workSubject, work); err != nil {
+		if errors.IsNotFound(err) {
+			logger.Info(fmt.Sprintf("%s not found", workSubject.String()))
+			return ctrl.Result{}, nil
+		}
+
+		logger.Error(err, fmt.Sprintf("%s fetch failed", workSubject.String()))
+		return ctrl.Result{}, err
+	}
+
+	phase := restorePhase(work)
+	if phase == "" {
+		logger.Info(fmt.Sprintf("%s no restore phase reported yet", workSubject.String()))
+		return ctrl.Result{}, nil
+	}
+	previousSpoke := work.GetLabels()[mcra.LabelRestoreFrom]
+
+	// the ResilientCluster resides in the cluster-namespace with a matching name, the replacement is recorded by the
+	// ClaimReconciler after the actions, requeue until it is
+	rcSubject := types.NamespacedName{Namespace: work.Namespace, Name: work.Namespace}
+	recorded, modified := false, false
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		rc := &apiv1.ResilientCluster{}
+		if err := r.Client.Get(ctx, rcSubject, rc); err != nil {
+			return err
+		}
+
+		if recorded, modified = recordRestorePhase(rc, previousSpoke, phase); !modified {
+			return nil
+		}
+		return r.Client.Update(ctx, rc)
+	})
+	if err != nil && !errors.IsNotFound(err) {
+		logger.Error(err, fmt.Sprintf("%s failed recording restore phase", rcSubject.String()))
+		return ctrl.Result{}, err
+	}
+	if !recorded {
+		// the ResilientCluster might be gone with its cluster being replaced, stop requeueing stale ManifestWorks
+		if time.Since(work.CreationTimestamp.Time) > restoreRecordTimeout {
+			logger.Info(fmt.Sprintf("%s replacement not recorded, giving up", rcSubject.String()))
+			return ctrl.Result{}, nil
+		}
+
+		logger.Info(fmt.Sprintf("%s replacement not recorded yet, requeueing", rcSubject.String()))
+		return ctrl.Result{RequeueAfter: restoreRequeueInterval}, nil
+	}
+	logger.Info(fmt.Sprintf("%s restore phase recorded", rcSubject.String()), "phase", phase)
+	if modified {
+		r.Recorder.Eventf(work, corev1.EventTypeNormal, "RestorePhase", "restore of %s is %s", previousSpoke, phase)
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// restorePhase is used for extracting the Velero Restore phase from the ManifestWork status feedback. Returns an empty
+// string if not reported.
+func restorePhase(work *workv1.ManifestWork) string {
+	for _, manifest := range work.Status.ResourceStatus.Manifests {
+		for _, value := range manifest.StatusFeedbacks.Values {
+			if value.Name == actions.RestorePhaseFeedback && value.Value.String != nil {
+				return *value.Value.String
+			}
+		}
+	}
+	return ""
+}
+
+// recordRestorePhase is used for setting the restore phase on the replacement of the previous Spoke, both the current
+// one and the one in the history. Returns true if the replacement was found, and true if modified.
+func recordRestorePhase(rc *apiv1.ResilientCluster, previousSpoke, phase string) (bool, bool) {
+	found, modified := false, false
+	record := func(replacement *apiv1.ReplacementStatus) {
+		if replacement.PreviousSpoke != previousSpoke || replacement.Restore == nil {
+			return
+		}
+		found = true
+		if replacement.Restore.Phase != phase {
+			replacement.Restore.Phase = phase
+			modified = true
+		}
+	}
+
+	if rc.Status.Replacement != nil {
+		record(rc.Status.Replacement)
+	}
+	for i := range rc.Status.History {
+		record(&rc.Status.History[i])
+	}
+	return found, modified
+}
+
+// init is registering the RestoreReconciler setup function for execution.
+func init() {
+	reconcilerFuncs = append(reconcilerFuncs, func(mgr manager.Manager, options Options) error {
+		return (&RestoreReconciler{
+			Client:   mgr.GetClient(),
+			Scheme:   mgr.GetScheme(),
+			Recorder: mgr.GetEventRecorderFor("mcra-restore-controller"),
+			Options:  options,
+		}).setupWithManager(mgr)
+	})
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2023 Red Hat, Inc.
+
+package reconcilers
+
+// This file hosts the RetentionReconciler implementation registering for Hive's ClusterDeployment CRs.
+
+import (
+	"context"
+	"fmt"
+	hivev1 "github.com/openshift/hive/apis/hive/v1"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/mcra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"time"
+)
+
+// RetentionReconciler is a receiver representing the MultiCluster-Resiliency-Addon operator reconciler for
+// ClusterDeployment CRs retained after their cluster was replaced.
+type RetentionReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	Options
+}
+
+// setupWithManager is used for setting up the controller named 'mcra-retention-controller' with the manager.
+// It uses predicates as event filters for verifying only handling ClusterDeployment CRs retained by us. The label is
+// verified against the updated object, as it is added by an update.
+func (r *RetentionReconciler) setupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("mcra-retention-controller").
+		For(&hivev1.ClusterDeployment{}).
+		WithEventFilter(predicate.NewPredicateFuncs(hasLabel(mcra.LabelRetained))).
+		Complete(r)
+}
+
+// +kubebuilder:rbac:groups=hive.openshift.io,resources=clusterdeployments,verbs=get;list;watch;delete
+
+// Reconcile is watching retained ClusterDeployment CRs, deleting them when their retention expires, and requeueing
+// them for the expiry otherwise.
+func (r *RetentionReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	deploymentSubject := types.NamespacedName{
+		Namespace: req.Namespace,
+		Name:      req.Name,
+	}
+
+	// fetch the ClusterDeployment cr, end loop if not found
+	deployment := &hivev1.ClusterDeployment{}
+	if err := r.Client.Get(ctx, deploymentSubject, deployment); err != nil {
+		if errors.IsNotFound(err) {
+			logger.Info(fmt.Sprintf("%s not found", deploymentSubject.String()))
+			return ctrl.Result{}, nil
+		}
+
+		logger.Error(err, fmt.Sprintf("%s fetch failed", deploymentSubject.String()))
+		return ctrl.Result{}, err
+	}
+
+	// already being deleted, nothing to do
+	if deployment.GetDeletionTimestamp() != nil {
+		return ctrl.Result{}, nil
+	}
+
+	// a malformed expiry is not retried, the ClusterDeployment is kept until the annotation is fixed
+	retainUntil, err := time.Parse(time.RFC3339, deployment.GetAnnotations()[mcra.AnnotationRetainUntil])
+	if err != nil {
+		logger.Error(err, fmt.Sprintf("%s has no valid retention expiry, keeping it", deploymentSubject.String()))
+		return ctrl.Result{}, nil
+	}
+
+	// requeue until the retention expires
+	if remaining := time.Until(retainUntil); remaining > 0 {
+		logger.Info(fmt.Sprintf("%s retained", deploymentSubject.String()), "retain-until", retainUntil)
+		return ctrl.Result{RequeueAfter: remaining}, nil
+	}
+
+	if err = r.Client.Delete(ctx, deployment); err != nil && !errors.IsNotFound(err) {
+		logger.Error(err, fmt.Sprintf("%s failed deleting expired ClusterDeployment", deploymentSubject.String()))
+		return ctrl.Result{}, err
+	}
+	logger.Info(fmt.Sprintf("%s retention expired, deleted", deploymentSubject.String()))
+	r.Recorder.Eventf(deployment, corev1.EventTypeNormal, "RetentionExpired", "retention expired at %s, deleted", retainUntil.Format(time.RFC3339))
+
+	return ctrl.Result{}, nil
+}
+
+// init is registering the RetentionReconciler setup function for execution.
+func init() {
+	reconcilerFuncs = append(reconcilerFuncs, func(mgr manager.Manager, options Options) error {
+		return (&RetentionReconciler{
+			Client:   mgr.GetClient(),
+			Scheme:   mgr.GetScheme(),
+			Recorder: mgr.GetEventRecorderFor("mcra-retention-controller"),
+			Options:  options,
+		}).setupWithManager(mgr)
+	})
+}
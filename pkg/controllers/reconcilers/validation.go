@@ -0,0 +1,354 @@
+// Copyright (c) 2023 Red Hat, Inc.
+
+package reconcilers
+
+// This file hosts the ValidationReconciler implementation registering for ResilientCluster CRs being validated.
+
+import (
+	"context"
+	"fmt"
+	apiv1 "github.com/rhecosystemappeng/multicluster-resiliency-addon/api/v1"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/controllers/actions"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/controllers/exporter"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/hooks"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/mcra"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/notify"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	addonv1alpha1 "open-cluster-management.io/api/addon/v1alpha1"
+	workv1 "open-cluster-management.io/api/work/v1"
+	"os"
+	"reflect"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"time"
+)
+
+const (
+	// validationRequeueInterval is the interval for re-checking a replacement Spoke cluster being validated.
+	validationRequeueInterval = 30 * time.Second
+	// defaultValidationTimeout is the time after which a replacement Spoke cluster failing its checks is Degraded.
+	defaultValidationTimeout = 30 * time.Minute
+)
+
+// ValidationReconciler is a receiver representing the MultiCluster-Resiliency-Addon operator reconciler for
+// ResilientCluster CRs with a replacement being validated.
+type ValidationReconciler struct {
+	client.Client
+	Reader   client.Reader
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	Options
+}
+
+// setupWithManager is used for setting up the controller named 'mcra-validation-controller' with the manager.
+// It uses predicates as event filters for verifying only handling ResilientCluster CRs with a replacement being
+// validated.
+func (r *ValidationReconciler) setupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("mcra-validation-controller").
+		For(&apiv1.ResilientCluster{}).
+		WithEventFilter(predicate.NewPredicateFuncs(isValidating)).
+		Complete(r)
+}
+
+// +kubebuilder:rbac:groups=appeng.ecosystem.redhat.com,resources=resilientclusters,verbs=get;list;watch;update
+// +kubebuilder:rbac:groups=addon.open-cluster-management.io,resources=managedclusteraddons,verbs=get;list;watch
+// +kubebuilder:rbac:groups=work.open-cluster-management.io,resources=manifestworks,verbs=get;list;watch
+// +kubebuilder:rbac:groups=policy.open-cluster-management.io,resources=policies,verbs=get;list;watch
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;delete
+
+// Reconcile is watching ResilientCluster CRs with a replacement being validated, invoking the post-failover hooks, and
+// checking the ManagedClusterAddOns, ManifestWorks, Policies, and smoke checks in the replacement Spoke's namespace.
+// The replacement is marked Replaced once the hooks finished and all checks pass, and Degraded if they don't pass
+// within the validation timeout.
+func (r *ValidationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	rcSubject := types.NamespacedName{
+		Namespace: req.Namespace,
+		Name:      req.Name,
+	}
+
+	// fetch the ResilientCluster cr, end loop if not found
+	rc := &apiv1.ResilientCluster{}
+	if err := r.Client.Get(ctx, rcSubject, rc); err != nil {
+		if errors.IsNotFound(err) {
+			logger.Info(fmt.Sprintf("%s not found", rcSubject.String()))
+			return ctrl.Result{}, nil
+		}
+
+		logger.Error(err, fmt.Sprintf("%s fetch failed", rcSubject.String()))
+		return ctrl.Result{}, err
+	}
+
+	// suspended replacements are validated once resumed, resuming triggers another reconcile
+	if !isValidating(rc) || isSuspended(rc) {
+		return ctrl.Result{}, nil
+	}
+
+	managerNamespace, exist := os.LookupEnv("POD_NAMESPACE")
+	if !exist {
+		return ctrl.Result{}, fmt.Errorf("unable to load manager namespace from POD_NAMESPACE")
+	}
+
+	config, err := loadConfiguration(ctx, r.Client, r.ConfigMapName, rc.Namespace, managerNamespace)
+	if err != nil {
+		logger.Error(err, "unable to load configuration")
+		return ctrl.Result{}, err
+	}
+	managerConfig, err := loadManagerConfiguration(ctx, r.Client, r.ConfigMapName, managerNamespace)
+	if err != nil && !errors.IsNotFound(err) {
+		logger.Error(err, "unable to load manager configuration")
+		return ctrl.Result{}, err
+	}
+	timeout := config.ValidationTimeout
+	if timeout == 0 {
+		timeout = defaultValidationTimeout
+	}
+
+	// invoke the post-failover hooks, resuming from the results recorded on the replacement, hooks are only accepted
+	// from the manager configuration as their jobs run in the manager namespace
+	previousSpoke := rc.Status.Replacement.PreviousSpoke
+	previousResults := phaseResults(rc.Status.Replacement.Hooks, apiv1.HookPostFailover)
+	hookResults, _, hooksPending := hooks.Run(ctx, r.Client, r.Reader, managerNamespace, managerConfig.Hooks, hooks.Payload{
+		Phase:       apiv1.HookPostFailover,
+		OldSpoke:    previousSpoke,
+		NewSpoke:    rc.Namespace,
+		LogicalName: logicalNameOf(rc),
+		Reason:      rc.Status.Replacement.Reason,
+	}, previousResults)
+
+	checks, err := r.validate(ctx, rc.Namespace)
+	if err != nil {
+		logger.Error(err, fmt.Sprintf("%s failed validating", rcSubject.String()))
+		return ctrl.Result{}, err
+	}
+
+	// decide the phase, keep validating until the hooks finished and all checks passed, or the timeout elapsed
+	phase := apiv1.ReplacementReplaced
+	if hooksPending {
+		phase = apiv1.ReplacementValidating
+	}
+	for _, check := range checks {
+		if !check.Passed {
+			phase = apiv1.ReplacementValidating
+			break
+		}
+	}
+	if phase == apiv1.ReplacementValidating && time.Since(rc.Status.Replacement.FinishTime.Time) > timeout {
+		phase = apiv1.ReplacementDegraded
+	}
+
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := r.Client.Get(ctx, rcSubject, rc); err != nil {
+			return err
+		}
+		if !recordValidation(rc, previousSpoke, phase, checks, hookResults) {
+			return nil
+		}
+		return r.Client.Update(ctx, rc)
+	})
+	if err != nil {
+		logger.Error(err, fmt.Sprintf("%s failed recording validation", rcSubject.String()))
+		return ctrl.Result{}, err
+	}
+
+	if phase == apiv1.ReplacementValidating {
+		logger.Info(fmt.Sprintf("%s replacement not validated yet, requeueing", rcSubject.String()))
+		if hooksPending {
+			return ctrl.Result{RequeueAfter: hooks.PollInterval}, nil
+		}
+		return ctrl.Result{RequeueAfter: validationRequeueInterval}, nil
+	}
+	logger.Info(fmt.Sprintf("%s replacement validated", rcSubject.String()), "phase", phase)
+	if phase == apiv1.ReplacementReplaced {
+		r.Recorder.Eventf(rc, corev1.EventTypeNormal, "Replaced", "replacement of %s validated, all %d checks passed", previousSpoke, len(checks))
+	} else {
+		r.Recorder.Eventf(rc, corev1.EventTypeWarning, "Degraded", "replacement of %s degraded, %d of %d checks passed", previousSpoke, passedChecks(checks), len(checks))
+	}
+
+	r.Exporter.Publish(ctx, exporter.TypeReplacementValidated, exporter.ClusterData{
+		Spoke:         rc.Namespace,
+		LogicalName:   logicalNameOf(rc),
+		PreviousSpoke: previousSpoke,
+		Phase:         phase,
+	})
+	notify.Send(ctx, r.Reader, managerNamespace, managerConfig.Notifiers, notify.Event{
+		Type:        notify.EventReplacementComplete,
+		Spoke:       previousSpoke,
+		NewSpoke:    rc.Namespace,
+		LogicalName: logicalNameOf(rc),
+		Message:     fmt.Sprintf("replacement is %s, %d of %d checks passed", phase, passedChecks(checks), len(checks)),
+	})
+
+	return ctrl.Result{}, nil
+}
+
+// validate is used for performing all checks against the resources in the replacement Spoke's namespace.
+func (r *ValidationReconciler) validate(ctx context.Context, namespace string) ([]apiv1.ValidationCheck, error) {
+	var checks []apiv1.ValidationCheck
+
+	addons := &addonv1alpha1.ManagedClusterAddOnList{}
+	if err := r.Client.List(ctx, addons, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	for _, addon := range addons.Items {
+		checks = append(checks, conditionCheck(apiv1.ValidationAddon, addon.Name, addon.Status.Conditions, addonv1alpha1.ManagedClusterAddOnConditionAvailable))
+	}
+
+	works := &workv1.ManifestWorkList{}
+	if err := r.Client.List(ctx, works, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	for _, work := range works.Items {
+		if checkName, found := work.GetLabels()[mcra.LabelSmokeCheck]; found {
+			checks = append(checks, smokeCheck(checkName, &work))
+		} else {
+			checks = append(checks, conditionCheck(apiv1.ValidationManifestWork, work.Name, work.Status.Conditions, workv1.WorkApplied))
+		}
+	}
+
+	// the policies replicated to the cluster-namespace, not installed with the governance framework is not a failure
+	policies := &unstructured.UnstructuredList{}
+	policies.SetAPIVersion("policy.open-cluster-management.io/v1")
+	policies.SetKind("PolicyList")
+	if err := r.Client.List(ctx, policies, client.InNamespace(namespace)); err != nil {
+		if !meta.IsNoMatchError(err) {
+			return nil, err
+		}
+	}
+	for _, policy := range policies.Items {
+		compliant, _, _ := unstructured.NestedString(policy.Object, "status", "compliant")
+		check := apiv1.ValidationCheck{Type: apiv1.ValidationPolicy, Name: policy.GetName(), Passed: compliant == "Compliant"}
+		if !check.Passed {
+			check.Message = fmt.Sprintf("compliance is '%s'", compliant)
+		}
+		checks = append(checks, check)
+	}
+
+	return checks, nil
+}
+
+// conditionCheck is used for building a check passing if the condition of the given type is true.
+func conditionCheck(checkType apiv1.ValidationCheckType, name string, conditions []metav1.Condition, conditionType string) apiv1.ValidationCheck {
+	check := apiv1.ValidationCheck{Type: checkType, Name: name}
+	condition := meta.FindStatusCondition(conditions, conditionType)
+	switch {
+	case condition == nil:
+		check.Message = fmt.Sprintf("%s condition not reported", conditionType)
+	case condition.Status != metav1.ConditionTrue:
+		check.Message = fmt.Sprintf("%s condition is %s, %s", conditionType, condition.Status, condition.Message)
+	default:
+		check.Passed = true
+	}
+	return check
+}
+
+// smokeCheck is used for building a check passing if the smoke check ManifestWork was applied, and all of its Jobs
+// reported succeeded pods.
+func smokeCheck(name string, work *workv1.ManifestWork) apiv1.ValidationCheck {
+	check := conditionCheck(apiv1.ValidationSmokeCheck, name, work.Status.Conditions, workv1.WorkApplied)
+	if !check.Passed {
+		return check
+	}
+
+	// ManifestConfigs are only set for Jobs, each is expected to report succeeded pods
+	succeeded := 0
+	for _, manifest := range work.Status.ResourceStatus.Manifests {
+		for _, value := range manifest.StatusFeedbacks.Values {
+			if value.Name == actions.SmokeCheckSucceededFeedback && value.Value.Integer != nil && *value.Value.Integer > 0 {
+				succeeded++
+			}
+		}
+	}
+	if succeeded < len(work.Spec.ManifestConfigs) {
+		check.Passed = false
+		check.Message = fmt.Sprintf("%d of %d jobs succeeded", succeeded, len(work.Spec.ManifestConfigs))
+	}
+	return check
+}
+
+// passedChecks is used for counting the passed checks.
+func passedChecks(checks []apiv1.ValidationCheck) int {
+	passed := 0
+	for _, check := range checks {
+		if check.Passed {
+			passed++
+		}
+	}
+	return passed
+}
+
+// recordValidation is used for setting the validation phase, checks, and post-failover hook results on the replacement
+// of the previous Spoke, both the current one and the one in the history. Returns true if modified.
+func recordValidation(rc *apiv1.ResilientCluster, previousSpoke string, phase apiv1.ReplacementPhase, checks []apiv1.ValidationCheck, hookResults []apiv1.HookResult) bool {
+	modified := false
+	record := func(replacement *apiv1.ReplacementStatus) {
+		if replacement.PreviousSpoke != previousSpoke || replacement.Phase != apiv1.ReplacementValidating {
+			return
+		}
+		if len(phaseResults(replacement.Hooks, apiv1.HookPostFailover)) < len(hookResults) {
+			replacement.Hooks = append(phaseResults(replacement.Hooks, apiv1.HookPreFailover), hookResults...)
+			modified = true
+		}
+		if replacement.Phase == phase && reflect.DeepEqual(replacement.Validation, checks) {
+			return
+		}
+		replacement.Phase = phase
+		replacement.Validation = checks
+		if phase != apiv1.ReplacementValidating {
+			now := metav1.Now()
+			replacement.ValidatedTime = &now
+		}
+		modified = true
+	}
+
+	if rc.Status.Replacement != nil {
+		record(rc.Status.Replacement)
+	}
+	for i := range rc.Status.History {
+		record(&rc.Status.History[i])
+	}
+	return modified
+}
+
+// phaseResults returns the hook results of the phase.
+func phaseResults(results []apiv1.HookResult, phase apiv1.HookPhase) []apiv1.HookResult {
+	var filtered []apiv1.HookResult
+	for _, result := range results {
+		if result.Phase == phase {
+			filtered = append(filtered, result)
+		}
+	}
+	return filtered
+}
+
+// isValidating returns true if the object is a ResilientCluster with a replacement being validated.
+func isValidating(obj client.Object) bool {
+	rc, ok := obj.(*apiv1.ResilientCluster)
+	return ok && rc.Status.Replacement != nil && rc.Status.Replacement.Phase == apiv1.ReplacementValidating
+}
+
+// init is registering the ValidationReconciler setup function for execution.
+func init() {
+	reconcilerFuncs = append(reconcilerFuncs, func(mgr manager.Manager, options Options) error {
+		return (&ValidationReconciler{
+			Client:   mgr.GetClient(),
+			Reader:   mgr.GetAPIReader(),
+			Scheme:   mgr.GetScheme(),
+			Recorder: mgr.GetEventRecorderFor("mcra-validation-controller"),
+			Options:  options,
+		}).setupWithManager(mgr)
+	})
+}
@@ -9,6 +9,8 @@ import (
 	"errors"
 	"fmt"
 	v1 "github.com/rhecosystemappeng/multicluster-resiliency-addon/api/v1"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/mcra"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -37,7 +39,12 @@ func (v *ValidateResilientCluster) ValidateCreate(ctx context.Context, obj runti
 }
 
 func (v *ValidateResilientCluster) ValidateUpdate(ctx context.Context, oldObj runtime.Object, newObj runtime.Object) (admission.Warnings, error) {
-	return nil, v.verifyUser(ctx)
+	err := v.verifyUser(ctx)
+	if err != nil && onlySuspendChanged(oldObj, newObj) {
+		// any user allowed to update ResilientClusters can suspend and resume them
+		return nil, nil
+	}
+	return nil, err
 }
 
 func (v *ValidateResilientCluster) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
@@ -63,6 +70,32 @@ func (v *ValidateResilientCluster) verifyUser(ctx context.Context) error {
 	return errors.New("user not allowed to control ResilientCluster")
 }
 
+// onlySuspendChanged is used for verifying the update only sets or removes the suspend annotation.
+func onlySuspendChanged(oldObj, newObj runtime.Object) bool {
+	oldRc, oldOk := oldObj.(*v1.ResilientCluster)
+	newRc, newOk := newObj.(*v1.ResilientCluster)
+	if !oldOk || !newOk {
+		return false
+	}
+
+	// compare copies, ignoring the suspend annotation and the fields maintained by the api server
+	normalize := func(rc *v1.ResilientCluster) *v1.ResilientCluster {
+		normalized := rc.DeepCopy()
+		annotations := normalized.GetAnnotations()
+		delete(annotations, mcra.AnnotationSuspend)
+		if len(annotations) == 0 {
+			annotations = nil
+		}
+		normalized.SetAnnotations(annotations)
+		normalized.SetResourceVersion("")
+		normalized.SetManagedFields(nil)
+		normalized.SetGeneration(0)
+		return normalized
+	}
+
+	return equality.Semantic.DeepEqual(normalize(oldRc), normalize(newRc))
+}
+
 // verifyOnlyOneInNamespace is used for verifying we don't already have a ResilientCluster resource in the target namespace.
 func (v *ValidateResilientCluster) verifyOnlyOneInNamespace(ctx context.Context) error {
 	rstcList := &v1.ResilientClusterList{}
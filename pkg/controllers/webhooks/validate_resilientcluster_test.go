@@ -0,0 +1,139 @@
+// Copyright (c) 2023 Red Hat, Inc.
+
+package webhooks
+
+import (
+	v1 "github.com/rhecosystemappeng/multicluster-resiliency-addon/api/v1"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/mcra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"testing"
+)
+
+func TestOnlySuspendChanged(t *testing.T) {
+	existing := &v1.ResilientCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "resilient-cluster",
+			Namespace:       "spoke1",
+			ResourceVersion: "1",
+			Generation:      1,
+			Labels:          map[string]string{"env": "prod"},
+			Annotations:     map[string]string{"example.com/owner": "payments"},
+			Finalizers:      []string{mcra.FinalizerResilientClusterCleanup},
+		},
+		Status: v1.ResilientClusterStatus{
+			CurrentStatus: v1.ClusterStatus{Availability: v1.ClusterAvailable},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		oldSuspended bool
+		modify       func(rc *v1.ResilientCluster)
+		newObj       runtime.Object
+		want         bool
+	}{
+		{
+			name:   "suspend set",
+			modify: func(rc *v1.ResilientCluster) { rc.Annotations[mcra.AnnotationSuspend] = "true" },
+			want:   true,
+		},
+		{
+			name: "suspend set with server maintained fields",
+			modify: func(rc *v1.ResilientCluster) {
+				rc.Annotations[mcra.AnnotationSuspend] = "true"
+				rc.ResourceVersion = "2"
+				rc.Generation = 2
+				rc.ManagedFields = []metav1.ManagedFieldsEntry{{Manager: "kubectl"}}
+			},
+			want: true,
+		},
+		{
+			name:         "suspend removed",
+			oldSuspended: true,
+			modify:       func(rc *v1.ResilientCluster) { delete(rc.Annotations, mcra.AnnotationSuspend) },
+			want:         true,
+		},
+		{
+			name:         "suspend removed with another annotation",
+			oldSuspended: true,
+			modify: func(rc *v1.ResilientCluster) {
+				delete(rc.Annotations, mcra.AnnotationSuspend)
+				delete(rc.Annotations, "example.com/owner")
+			},
+			want: false,
+		},
+		{
+			name:   "no change",
+			modify: func(rc *v1.ResilientCluster) {},
+			want:   true,
+		},
+		{
+			name: "another annotation changed",
+			modify: func(rc *v1.ResilientCluster) {
+				rc.Annotations[mcra.AnnotationSuspend] = "true"
+				rc.Annotations["example.com/owner"] = "billing"
+			},
+			want: false,
+		},
+		{
+			name: "another annotation added",
+			modify: func(rc *v1.ResilientCluster) {
+				rc.Annotations[mcra.AnnotationFailoverDeferred] = "suspended"
+			},
+			want: false,
+		},
+		{
+			name: "all other annotations removed",
+			modify: func(rc *v1.ResilientCluster) {
+				rc.Annotations = map[string]string{mcra.AnnotationSuspend: "true"}
+			},
+			want: false,
+		},
+		{
+			name: "label changed",
+			modify: func(rc *v1.ResilientCluster) {
+				rc.Annotations[mcra.AnnotationSuspend] = "true"
+				rc.Labels["env"] = "dev"
+			},
+			want: false,
+		},
+		{
+			name:   "finalizer removed",
+			modify: func(rc *v1.ResilientCluster) { rc.Finalizers = nil },
+			want:   false,
+		},
+		{
+			name: "status changed",
+			modify: func(rc *v1.ResilientCluster) {
+				rc.Annotations[mcra.AnnotationSuspend] = "true"
+				rc.Status.CurrentStatus.Availability = v1.ClusterNotAvailable
+			},
+			want: false,
+		},
+		{
+			name:   "not a resilient cluster",
+			newObj: &corev1.ConfigMap{},
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldRc := existing.DeepCopy()
+			if tt.oldSuspended {
+				oldRc.Annotations[mcra.AnnotationSuspend] = "true"
+			}
+			newObj := tt.newObj
+			if newObj == nil {
+				updated := oldRc.DeepCopy()
+				tt.modify(updated)
+				newObj = updated
+			}
+			if got := onlySuspendChanged(oldRc, newObj); got != tt.want {
+				t.Errorf("onlySuspendChanged() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
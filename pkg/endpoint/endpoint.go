@@ -0,0 +1,54 @@
+// Copyright (c) 2023 Red Hat, Inc.
+
+package endpoint
+
+// This file contains types and functions for POSTing to HTTP endpoints, shared by the hooks and the notifiers.
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"net/http"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Endpoint describes an HTTP endpoint POSTed to. A 2xx response status is a success.
+type Endpoint struct {
+	URL string `json:"url"`
+	// HeadersSecret is optionally the name of a Secret in the manager namespace, its entries are set as headers,
+	// i.e. 'Authorization'.
+	HeadersSecret string `json:"headersSecret,omitempty"`
+}
+
+// Post is used for POSTing the body to the Endpoint's URL. The headers secret is read from the namespace through the
+// reader, which is expected not to be cached, as the manager is not watching Secrets.
+func (e *Endpoint) Post(ctx context.Context, reader client.Reader, namespace, contentType string, body []byte) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, e.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", contentType)
+
+	if e.HeadersSecret != "" {
+		secret := &corev1.Secret{}
+		if err = reader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: e.HeadersSecret}, secret); err != nil {
+			return fmt.Errorf("failed fetching headers secret %s, %v", e.HeadersSecret, err)
+		}
+		for key, value := range secret.Data {
+			request.Header.Set(key, string(value))
+		}
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("%s responded with %s", e.URL, response.Status)
+	}
+	return nil
+}
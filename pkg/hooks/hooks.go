@@ -0,0 +1,152 @@
+// Copyright (c) 2023 Red Hat, Inc.
+
+package hooks
+
+// This file contains types and functions for invoking hooks before and after replacing a Spoke cluster.
+
+import (
+	"context"
+	"fmt"
+	apiv1 "github.com/rhecosystemappeng/multicluster-resiliency-addon/api/v1"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/endpoint"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultTimeout is the time a hook is allowed to run if the hook sets no timeout.
+	DefaultTimeout = 5 * time.Minute
+	// MaxWebhookTimeout caps the time a webhook is waited for, webhooks are invoked while reconciling.
+	MaxWebhookTimeout = 30 * time.Second
+	// PollInterval is the interval for resuming a run pending on a Job.
+	PollInterval = 10 * time.Second
+)
+
+type (
+	// Hook describes custom logic invoked before or after replacing a Spoke cluster, either a Job run on the Hub, or
+	// an HTTP webhook. Exactly one of Job and Webhook is required.
+	Hook struct {
+		Name string `json:"name"`
+		// Phase is either apiv1.HookPreFailover or apiv1.HookPostFailover.
+		Phase apiv1.HookPhase `json:"phase"`
+		// Timeout optionally overrides DefaultTimeout, capped by MaxWebhookTimeout for webhooks, a timed out hook is
+		// failed.
+		Timeout metav1.Duration `json:"timeout,omitempty"`
+		// Veto makes a failed PreFailover hook veto the failover.
+		Veto bool             `json:"veto,omitempty"`
+		Job  *batchv1.JobSpec `json:"job,omitempty"`
+		// Webhook is invoked with a POST request carrying the Payload as JSON.
+		Webhook *endpoint.Endpoint `json:"webhook,omitempty"`
+	}
+
+	// Payload describes the replacement a hook is invoked for. The NewSpoke is only known to PostFailover hooks.
+	Payload struct {
+		Phase       apiv1.HookPhase `json:"phase"`
+		OldSpoke    string          `json:"oldSpoke"`
+		NewSpoke    string          `json:"newSpoke,omitempty"`
+		LogicalName string          `json:"logicalName"`
+		Reason      string          `json:"reason,omitempty"`
+	}
+)
+
+// Validate is used for verifying a Hook is well-defined.
+func (h *Hook) Validate() error {
+	if errs := validation.IsDNS1123Label(h.Name); len(errs) > 0 {
+		return fmt.Errorf("hook name %s is invalid, %s", h.Name, strings.Join(errs, ", "))
+	}
+	if h.Phase != apiv1.HookPreFailover && h.Phase != apiv1.HookPostFailover {
+		return fmt.Errorf("hook %s has unknown phase %s", h.Name, h.Phase)
+	}
+	if (h.Job == nil) == (h.Webhook == nil) {
+		return fmt.Errorf("hook %s requires exactly one of job and webhook", h.Name)
+	}
+	if h.Webhook != nil && h.Webhook.URL == "" {
+		return fmt.Errorf("hook %s webhook url is required", h.Name)
+	}
+	return nil
+}
+
+// timeout returns the Hook's timeout, or DefaultTimeout if not set.
+func (h *Hook) timeout() time.Duration {
+	if h.Timeout.Duration > 0 {
+		return h.Timeout.Duration
+	}
+	return DefaultTimeout
+}
+
+// webhookTimeout returns the Hook's timeout, capped by MaxWebhookTimeout.
+func (h *Hook) webhookTimeout() time.Duration {
+	if timeout := h.timeout(); timeout < MaxWebhookTimeout {
+		return timeout
+	}
+	return MaxWebhookTimeout
+}
+
+// Run is used for invoking, in order, the hooks of the Payload's phase, resuming a run from its previous results.
+// Jobs are created in the namespace, which is also where webhook headers secrets are loaded from, and are read through
+// the reader for not racing the cache. A Job still running leaves the run pending, to be resumed by a later call. A
+// failed PreFailover hook set with Veto stops the run and vetoes the failover. Returns the results of the previous and
+// the invoked hooks, true if vetoed, and true if pending.
+func Run(ctx context.Context, c client.Client, reader client.Reader, namespace string, hooks []Hook, payload Payload, previous []apiv1.HookResult) ([]apiv1.HookResult, bool, bool) {
+	logger := log.FromContext(ctx)
+
+	results := append([]apiv1.HookResult{}, previous...)
+	for _, hook := range hooks {
+		if hook.Phase != payload.Phase {
+			continue
+		}
+
+		if result, done := findResult(previous, hook.Name); done {
+			if result.Vetoed {
+				return results, true, false
+			}
+			continue
+		}
+
+		var err error
+		result := apiv1.HookResult{Name: hook.Name, Phase: hook.Phase}
+		if hook.Job != nil {
+			var finished bool
+			if finished, result.StartTime, err = runJob(ctx, c, reader, namespace, hook, payload); !finished {
+				logger.Info("hook pending", "hook", hook.Name, "phase", hook.Phase, "old-spoke", payload.OldSpoke)
+				return results, false, true
+			}
+		} else {
+			logger.Info("invoking hook", "hook", hook.Name, "phase", hook.Phase, "old-spoke", payload.OldSpoke)
+			result.StartTime = metav1.Now()
+			hookCtx, cancel := context.WithTimeout(ctx, hook.webhookTimeout())
+			err = callWebhook(hookCtx, reader, namespace, hook, payload)
+			cancel()
+		}
+
+		result.FinishTime = metav1.Now()
+		result.Succeeded = err == nil
+		if err != nil {
+			logger.Error(err, "hook failed", "hook", hook.Name, "phase", hook.Phase, "old-spoke", payload.OldSpoke)
+			result.Message = err.Error()
+			result.Vetoed = hook.Veto && hook.Phase == apiv1.HookPreFailover
+		}
+		results = append(results, result)
+
+		if result.Vetoed {
+			logger.Info("failover vetoed by hook", "hook", hook.Name, "old-spoke", payload.OldSpoke)
+			return results, true, false
+		}
+	}
+	return results, false, false
+}
+
+// findResult returns the result of the named hook, and true if found.
+func findResult(results []apiv1.HookResult, name string) (apiv1.HookResult, bool) {
+	for _, result := range results {
+		if result.Name == name {
+			return result, true
+		}
+	}
+	return apiv1.HookResult{}, false
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2023 Red Hat, Inc.
+
+package hooks
+
+// This file contains functions for invoking hooks as Jobs on the Hub.
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/mcra"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"time"
+)
+
+// jobTTLSeconds is the time finished hook Jobs are kept for, if the Job template sets none.
+const jobTTLSeconds = int32(3600)
+
+// runJob is used for creating a Job from the Hook's template, or checking on the Job created by a previous call. The
+// Job is named after the Hook and the Payload, so every failover attempt gets a single Job. The Payload is passed to
+// the Job's containers as environment variables, i.e. MCRA_OLD_SPOKE. A timed out Job is deleted. Returns true if the
+// Job finished, the Job's creation time, and an error if the Job failed.
+func runJob(ctx context.Context, c client.Client, reader client.Reader, namespace string, hook Hook, payload Payload) (bool, metav1.Time, error) {
+	name := jobName(hook, payload)
+
+	current := &batchv1.Job{}
+	if err := reader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, current); err != nil {
+		if !errors.IsNotFound(err) {
+			return true, metav1.Now(), fmt.Errorf("failed fetching job %s, %v", name, err)
+		}
+		log.FromContext(ctx).Info("creating hook job", "hook", hook.Name, "job", name)
+		if err = c.Create(ctx, newJob(name, namespace, hook, payload)); err != nil && !errors.IsAlreadyExists(err) {
+			return true, metav1.Now(), err
+		}
+		return false, metav1.Now(), nil
+	}
+
+	for _, condition := range current.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			return true, current.CreationTimestamp, nil
+		case batchv1.JobFailed:
+			return true, current.CreationTimestamp, fmt.Errorf("job %s failed, %s", name, condition.Message)
+		}
+	}
+
+	if time.Since(current.CreationTimestamp.Time) > hook.timeout() {
+		propagation := client.PropagationPolicy("Background")
+		if err := c.Delete(ctx, current, propagation); err != nil && !errors.IsNotFound(err) {
+			log.FromContext(ctx).Error(err, "failed deleting timed out hook job", "job", name)
+		}
+		return true, current.CreationTimestamp, fmt.Errorf("job %s did not finish in %s", name, hook.timeout())
+	}
+	return false, current.CreationTimestamp, nil
+}
+
+// jobName returns the name of the Job for the Hook and the Payload, suffixed with a hash of the Payload.
+func jobName(hook Hook, payload Payload) string {
+	serialized, _ := json.Marshal(payload)
+	sum := sha256.Sum256(serialized)
+	return fmt.Sprintf("mcra-hook-%s-%s", hook.Name, hex.EncodeToString(sum[:])[:8])
+}
+
+// newJob is used for building the Job from the Hook's template.
+func newJob(name, namespace string, hook Hook, payload Payload) *batchv1.Job {
+	job := &batchv1.Job{}
+	job.SetName(name)
+	job.SetNamespace(namespace)
+	job.SetLabels(map[string]string{mcra.LabelHook: hook.Name})
+	job.SetAnnotations(map[string]string{mcra.AnnotationCreatedBy: mcra.AddonName})
+	job.Spec = *hook.Job.DeepCopy()
+
+	if job.Spec.TTLSecondsAfterFinished == nil {
+		ttl := jobTTLSeconds
+		job.Spec.TTLSecondsAfterFinished = &ttl
+	}
+	if job.Spec.Template.Spec.RestartPolicy == "" {
+		job.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyNever
+	}
+
+	env := []corev1.EnvVar{
+		{Name: "MCRA_PHASE", Value: string(payload.Phase)},
+		{Name: "MCRA_OLD_SPOKE", Value: payload.OldSpoke},
+		{Name: "MCRA_NEW_SPOKE", Value: payload.NewSpoke},
+		{Name: "MCRA_LOGICAL_NAME", Value: payload.LogicalName},
+		{Name: "MCRA_REASON", Value: payload.Reason},
+	}
+	for i := range job.Spec.Template.Spec.Containers {
+		job.Spec.Template.Spec.Containers[i].Env = append(job.Spec.Template.Spec.Containers[i].Env, env...)
+	}
+	return job
+}
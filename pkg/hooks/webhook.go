@@ -0,0 +1,21 @@
+// Copyright (c) 2023 Red Hat, Inc.
+
+package hooks
+
+// This file contains functions for invoking hooks as HTTP webhooks.
+
+import (
+	"context"
+	"encoding/json"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// callWebhook is used for POSTing the Payload as JSON to the Hook's webhook URL. The headers are loaded from the
+// webhook's headers secret in the namespace, if set.
+func callWebhook(ctx context.Context, reader client.Reader, namespace string, hook Hook, payload Payload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return hook.Webhook.Post(ctx, reader, namespace, "application/json", body)
+}
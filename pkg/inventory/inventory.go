@@ -0,0 +1,119 @@
+// Copyright (c) 2023 Red Hat, Inc.
+
+package inventory
+
+// This file contains types and functions for describing the workload inventory of a Spoke cluster. The inventory is
+// collected by the Addon Agent and stored in a ConfigMap in the Spoke's cluster-namespace on the Hub.
+
+import (
+	"encoding/json"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DataKey is the key in the inventory ConfigMap's data holding the serialized Inventory.
+const DataKey = "inventory.json"
+
+// ReplicationType is the type of replication protecting a PersistentVolumeClaim's data, use ReplicationVolSync and
+// ReplicationVolumeReplication.
+type ReplicationType string
+
+const (
+	ReplicationVolSync           ReplicationType = "VolSync"
+	ReplicationVolumeReplication ReplicationType = "VolumeReplication"
+)
+
+type (
+	// Inventory is a snapshot of the workloads running on a Spoke cluster at a specific time.
+	Inventory struct {
+		SpokeName  string      `json:"spokeName"`
+		Time       metav1.Time `json:"time"`
+		Namespaces []Namespace `json:"namespaces"`
+	}
+
+	// Namespace encapsulates the workloads found in a single namespace on the Spoke.
+	Namespace struct {
+		Name                   string     `json:"name"`
+		Deployments            []Workload `json:"deployments,omitempty"`
+		StatefulSets           []Workload `json:"statefulSets,omitempty"`
+		PersistentVolumeClaims []Claim    `json:"persistentVolumeClaims,omitempty"`
+		Routes                 []Route    `json:"routes,omitempty"`
+	}
+
+	// Workload represents a Deployment or a StatefulSet, and the images used by its containers.
+	Workload struct {
+		Name     string   `json:"name"`
+		Replicas int32    `json:"replicas"`
+		Images   []string `json:"images,omitempty"`
+	}
+
+	// Claim represents a PersistentVolumeClaim, and the replication of its data if replicated.
+	Claim struct {
+		Name         string       `json:"name"`
+		StorageClass string       `json:"storageClass,omitempty"`
+		Capacity     string       `json:"capacity,omitempty"`
+		AccessModes  []string     `json:"accessModes,omitempty"`
+		Replication  *Replication `json:"replication,omitempty"`
+	}
+
+	// Replication represents a VolSync ReplicationSource or a VolumeReplication replicating a PersistentVolumeClaim.
+	// The Class is the VolumeReplicationClass, the Mover and Repository are of the VolSync ReplicationSource, the
+	// Repository is only set for the restic mover.
+	Replication struct {
+		Type       ReplicationType `json:"type"`
+		Name       string          `json:"name"`
+		Class      string          `json:"class,omitempty"`
+		Mover      string          `json:"mover,omitempty"`
+		Repository string          `json:"repository,omitempty"`
+	}
+
+	// Route represents an OpenShift Route.
+	Route struct {
+		Name string `json:"name"`
+		Host string `json:"host,omitempty"`
+	}
+)
+
+// ToConfigMapData is used for serializing the Inventory into a ConfigMap data map.
+func (i *Inventory) ToConfigMapData() (map[string]string, error) {
+	data, err := json.Marshal(i)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{DataKey: string(data)}, nil
+}
+
+// FromConfigMap is used for deserializing an Inventory from a ConfigMap created with ToConfigMapData.
+func FromConfigMap(configMap *corev1.ConfigMap) (*Inventory, error) {
+	inv := &Inventory{}
+	if err := json.Unmarshal([]byte(configMap.Data[DataKey]), inv); err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+// ReplicatedClaims is used for listing the PersistentVolumeClaims with a replication, as 'namespace/name'.
+func (i *Inventory) ReplicatedClaims() []string {
+	var replicated []string
+	for _, ns := range i.Namespaces {
+		for _, claim := range ns.PersistentVolumeClaims {
+			if claim.Replication != nil {
+				replicated = append(replicated, ns.Name+"/"+claim.Name)
+			}
+		}
+	}
+	return replicated
+}
+
+// UnreplicatedClaims is used for listing the PersistentVolumeClaims with no replication, as 'namespace/name'.
+func (i *Inventory) UnreplicatedClaims() []string {
+	var unreplicated []string
+	for _, ns := range i.Namespaces {
+		for _, claim := range ns.PersistentVolumeClaims {
+			if claim.Replication == nil {
+				unreplicated = append(unreplicated, ns.Name+"/"+claim.Name)
+			}
+		}
+	}
+	return unreplicated
+}
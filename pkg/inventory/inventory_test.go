@@ -0,0 +1,38 @@
+// Copyright (c) 2023 Red Hat, Inc.
+
+package inventory
+
+import (
+	"k8s.io/apimachinery/pkg/api/equality"
+	"testing"
+)
+
+func TestReplicatedAndUnreplicatedClaims(t *testing.T) {
+	inv := &Inventory{Namespaces: []Namespace{
+		{
+			Name: "payments",
+			PersistentVolumeClaims: []Claim{
+				{Name: "db", Replication: &Replication{Type: ReplicationVolumeReplication, Name: "db-replication"}},
+				{Name: "cache"},
+			},
+		},
+		{Name: "frontend"},
+		{
+			Name: "orders",
+			PersistentVolumeClaims: []Claim{
+				{Name: "data", Replication: &Replication{Type: ReplicationVolSync, Name: "data-source", Mover: "rsync"}},
+				{Name: "scratch"},
+			},
+		},
+	}}
+
+	if got, want := inv.ReplicatedClaims(), []string{"payments/db", "orders/data"}; !equality.Semantic.DeepEqual(got, want) {
+		t.Errorf("ReplicatedClaims() = %v, want %v", got, want)
+	}
+	if got, want := inv.UnreplicatedClaims(), []string{"payments/cache", "orders/scratch"}; !equality.Semantic.DeepEqual(got, want) {
+		t.Errorf("UnreplicatedClaims() = %v, want %v", got, want)
+	}
+	if got := (&Inventory{}).UnreplicatedClaims(); got != nil {
+		t.Errorf("UnreplicatedClaims() of an empty inventory = %v, want nil", got)
+	}
+}
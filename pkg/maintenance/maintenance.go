@@ -0,0 +1,116 @@
+// Copyright (c) 2023 Red Hat, Inc.
+
+package maintenance
+
+// This file contains types and functions for evaluating maintenance windows and blackout periods.
+
+import (
+	"fmt"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/mcra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"time"
+)
+
+// MaxDuration is the maximum duration of a recurring Window.
+const MaxDuration = 7 * 24 * time.Hour
+
+// Window describes a period of time, either recurring per a cron Schedule for a Duration, or an explicit Start and
+// End. Schedules are evaluated in UTC. Exactly one of Schedule and Start is required.
+type Window struct {
+	Name     string          `json:"name"`
+	Schedule string          `json:"schedule,omitempty"`
+	Duration metav1.Duration `json:"duration,omitempty"`
+	Start    *metav1.Time    `json:"start,omitempty"`
+	End      *metav1.Time    `json:"end,omitempty"`
+}
+
+// Validate is used for verifying a Window is well-defined.
+func (w *Window) Validate() error {
+	if (w.Schedule == "") == (w.Start == nil) {
+		return fmt.Errorf("window %s requires exactly one of schedule and start", w.Name)
+	}
+	if w.Start != nil {
+		if w.End == nil || !w.Start.Before(w.End) {
+			return fmt.Errorf("window %s requires an end after its start", w.Name)
+		}
+		return nil
+	}
+	if _, err := parseSchedule(w.Schedule); err != nil {
+		return fmt.Errorf("window %s %v", w.Name, err)
+	}
+	if w.Duration.Duration <= 0 || w.Duration.Duration > MaxDuration {
+		return fmt.Errorf("window %s requires a duration up to %s", w.Name, MaxDuration)
+	}
+	return nil
+}
+
+// ActiveUntil returns the end of the Window if it is active at the time, or the zero time if not. For a recurring
+// Window, the end is of the latest occurrence active at the time.
+func (w *Window) ActiveUntil(now time.Time) time.Time {
+	if w.Start != nil {
+		if w.End != nil && !now.Before(w.Start.Time) && now.Before(w.End.Time) {
+			return w.End.Time
+		}
+		return time.Time{}
+	}
+
+	sched, err := parseSchedule(w.Schedule)
+	if err != nil {
+		return time.Time{}
+	}
+	// walk back minute by minute looking for the latest occurrence still running
+	now = now.UTC()
+	for occurrence := now.Truncate(time.Minute); now.Sub(occurrence) < w.Duration.Duration; occurrence = occurrence.Add(-time.Minute) {
+		if sched.matches(occurrence) {
+			return occurrence.Add(w.Duration.Duration)
+		}
+	}
+	return time.Time{}
+}
+
+// Active is used for finding a Window active at the time. Returns the active Window ending last and its end, or nil if
+// none is active.
+func Active(windows []Window, now time.Time) (*Window, time.Time) {
+	var active *Window
+	var end time.Time
+	for i := range windows {
+		if until := windows[i].ActiveUntil(now); until.After(end) {
+			active, end = &windows[i], until
+		}
+	}
+	return active, end
+}
+
+// FromAnnotations is used for extracting a per-cluster maintenance Window from the annotations, i.e. of a
+// ManagedCluster. Either a start and end, or a schedule and a duration. Returns nil if not annotated.
+func FromAnnotations(name string, annotations map[string]string) (*Window, error) {
+	window := &Window{Name: name}
+
+	if schedule, found := annotations[mcra.AnnotationMaintenanceSchedule]; found {
+		window.Schedule = schedule
+		duration, err := time.ParseDuration(annotations[mcra.AnnotationMaintenanceDuration])
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing %s, %v", mcra.AnnotationMaintenanceDuration, err)
+		}
+		window.Duration = metav1.Duration{Duration: duration}
+	}
+
+	if start, found := annotations[mcra.AnnotationMaintenanceStart]; found {
+		parsed, err := time.Parse(time.RFC3339, start)
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing %s, %v", mcra.AnnotationMaintenanceStart, err)
+		}
+		window.Start = &metav1.Time{Time: parsed}
+		if end, found := annotations[mcra.AnnotationMaintenanceEnd]; found {
+			if parsed, err = time.Parse(time.RFC3339, end); err != nil {
+				return nil, fmt.Errorf("failed parsing %s, %v", mcra.AnnotationMaintenanceEnd, err)
+			}
+			window.End = &metav1.Time{Time: parsed}
+		}
+	}
+
+	if window.Schedule == "" && window.Start == nil {
+		return nil, nil
+	}
+	return window, window.Validate()
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2023 Red Hat, Inc.
+
+package maintenance
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+	"time"
+)
+
+func TestActiveUntil(t *testing.T) {
+	// a nightly window from 23:00 for 3 hours, wrapping midnight
+	nightly := Window{Name: "nightly", Schedule: "0 23 * * *", Duration: metav1.Duration{Duration: 3 * time.Hour}}
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2024, time.January, day, hour, minute, 0, 0, time.UTC)
+	}
+	start := metav1.NewTime(at(1, 10, 0))
+	end := metav1.NewTime(at(1, 12, 0))
+	explicit := Window{Name: "explicit", Start: &start, End: &end}
+
+	tests := []struct {
+		name   string
+		window Window
+		now    time.Time
+		want   time.Time
+	}{
+		{name: "before the nightly window", window: nightly, now: at(1, 22, 59), want: time.Time{}},
+		{name: "nightly window start", window: nightly, now: at(1, 23, 0), want: at(2, 2, 0)},
+		{name: "nightly window before midnight", window: nightly, now: at(1, 23, 59), want: at(2, 2, 0)},
+		{name: "nightly window at midnight", window: nightly, now: at(2, 0, 0), want: at(2, 2, 0)},
+		{name: "nightly window after midnight", window: nightly, now: at(2, 1, 59), want: at(2, 2, 0)},
+		{name: "nightly window end", window: nightly, now: at(2, 2, 0), want: time.Time{}},
+		{name: "nightly window in another zone", window: nightly, now: at(2, 1, 0).In(time.FixedZone("UTC+5", 5*3600)), want: at(2, 2, 0)},
+		{name: "before the explicit window", window: explicit, now: at(1, 9, 59), want: time.Time{}},
+		{name: "explicit window start", window: explicit, now: at(1, 10, 0), want: at(1, 12, 0)},
+		{name: "explicit window end", window: explicit, now: at(1, 12, 0), want: time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.window.ActiveUntil(tt.now); !got.Equal(tt.want) {
+				t.Errorf("ActiveUntil(%s) = %s, want %s", tt.now, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestActive(t *testing.T) {
+	now := time.Date(2024, time.January, 2, 1, 0, 0, 0, time.UTC)
+	windows := []Window{
+		{Name: "short", Schedule: "0 0 * * *", Duration: metav1.Duration{Duration: 2 * time.Hour}},
+		{Name: "long", Schedule: "0 23 * * *", Duration: metav1.Duration{Duration: 5 * time.Hour}},
+		{Name: "inactive", Schedule: "0 12 * * *", Duration: metav1.Duration{Duration: time.Hour}},
+	}
+
+	active, end := Active(windows, now)
+	if active == nil || active.Name != "long" {
+		t.Fatalf("Active() = %v, want the long window", active)
+	}
+	if want := time.Date(2024, time.January, 2, 4, 0, 0, 0, time.UTC); !end.Equal(want) {
+		t.Errorf("Active() end = %s, want %s", end, want)
+	}
+
+	if active, _ = Active(windows[2:], now); active != nil {
+		t.Errorf("Active() = %v, want none", active)
+	}
+}
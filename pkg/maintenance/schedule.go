@@ -0,0 +1,130 @@
+// Copyright (c) 2023 Red Hat, Inc.
+
+package maintenance
+
+// This file contains a minimal cron expression parser for recurring windows.
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// schedule is a parsed cron expression, i.e. '0 2 * * 6'. Every field is a bit set of its matching values.
+type schedule struct {
+	minute, hour, dayOfMonth, month, dayOfWeek uint64
+	// a day matches either day field if both are restricted, per the cron semantics
+	anyDayOfMonth, anyDayOfWeek bool
+}
+
+// field describes the bounds of a cron expression field.
+type field struct {
+	name     string
+	min, max int
+}
+
+var fields = []field{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+// parseSchedule is used for parsing a standard 5 fields cron expression, minute, hour, day of month, month, and day of
+// week. Fields support '*', values, ranges, steps, and comma-separated lists of them, i.e. '*/15 1-5 * * 1,3'. Day of
+// week 7 is Sunday, same as 0.
+func parseSchedule(expression string) (*schedule, error) {
+	parts := strings.Fields(expression)
+	if len(parts) != len(fields) {
+		return nil, fmt.Errorf("schedule %q requires %d fields, got %d", expression, len(fields), len(parts))
+	}
+
+	sets := make([]uint64, len(fields))
+	for i, part := range parts {
+		set, err := parseField(part, fields[i])
+		if err != nil {
+			return nil, fmt.Errorf("schedule %q %v", expression, err)
+		}
+		sets[i] = set
+	}
+
+	// fold sunday as 7 into sunday as 0
+	if sets[4]&(1<<7) != 0 {
+		sets[4] |= 1
+	}
+
+	return &schedule{
+		minute:        sets[0],
+		hour:          sets[1],
+		dayOfMonth:    sets[2],
+		month:         sets[3],
+		dayOfWeek:     sets[4],
+		anyDayOfMonth: parts[2] == "*",
+		anyDayOfWeek:  parts[4] == "*",
+	}, nil
+}
+
+// parseField is used for parsing a single cron expression field into a bit set of its matching values.
+func parseField(value string, f field) (uint64, error) {
+	var set uint64
+	for _, member := range strings.Split(value, ",") {
+		rangePart, step := member, 1
+		if idx := strings.Index(member, "/"); idx >= 0 {
+			parsed, err := strconv.Atoi(member[idx+1:])
+			if err != nil || parsed < 1 {
+				return 0, fmt.Errorf("has invalid %s step %s", f.name, member)
+			}
+			rangePart, step = member[:idx], parsed
+		}
+
+		start, end := f.min, f.max
+		if rangePart != "*" {
+			bounds := strings.SplitN(rangePart, "-", 2)
+			parsed, err := strconv.Atoi(bounds[0])
+			if err != nil {
+				return 0, fmt.Errorf("has invalid %s %s", f.name, member)
+			}
+			start, end = parsed, parsed
+			if len(bounds) == 2 {
+				if end, err = strconv.Atoi(bounds[1]); err != nil {
+					return 0, fmt.Errorf("has invalid %s %s", f.name, member)
+				}
+			} else if step > 1 {
+				// a step from a single value runs to the end of the field, i.e. '5/15'
+				end = f.max
+			}
+		}
+
+		if start < f.min || end > f.max || start > end {
+			return 0, fmt.Errorf("has %s %s out of range %d-%d", f.name, member, f.min, f.max)
+		}
+		for v := start; v <= end; v += step {
+			set |= 1 << uint(v)
+		}
+	}
+	return set, nil
+}
+
+// matches returns true if the time, truncated to the minute, matches the schedule.
+func (s *schedule) matches(t time.Time) bool {
+	if s.minute&(1<<uint(t.Minute())) == 0 ||
+		s.hour&(1<<uint(t.Hour())) == 0 ||
+		s.month&(1<<uint(t.Month())) == 0 {
+		return false
+	}
+
+	dayOfMonth := s.dayOfMonth&(1<<uint(t.Day())) != 0
+	dayOfWeek := s.dayOfWeek&(1<<uint(t.Weekday())) != 0
+	switch {
+	case s.anyDayOfMonth && s.anyDayOfWeek:
+		return true
+	case s.anyDayOfMonth:
+		return dayOfWeek
+	case s.anyDayOfWeek:
+		return dayOfMonth
+	default:
+		return dayOfMonth || dayOfWeek
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2023 Red Hat, Inc.
+
+package maintenance
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseScheduleInvalid(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+	}{
+		{name: "empty", expression: ""},
+		{name: "too few fields", expression: "0 2 * *"},
+		{name: "too many fields", expression: "0 2 * * * *"},
+		{name: "minute out of range", expression: "60 * * * *"},
+		{name: "hour out of range", expression: "0 24 * * *"},
+		{name: "day of month zero", expression: "0 0 0 * *"},
+		{name: "month out of range", expression: "0 0 * 13 *"},
+		{name: "day of week out of range", expression: "0 0 * * 8"},
+		{name: "reversed range", expression: "0 5-1 * * *"},
+		{name: "zero step", expression: "*/0 * * * *"},
+		{name: "invalid step", expression: "*/x * * * *"},
+		{name: "not a number", expression: "a * * * *"},
+		{name: "invalid range end", expression: "0 1-x * * *"},
+		{name: "names are not supported", expression: "0 0 * * MON"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseSchedule(tt.expression); err == nil {
+				t.Errorf("parseSchedule(%q) expected an error", tt.expression)
+			}
+		})
+	}
+}
+
+func TestScheduleMatches(t *testing.T) {
+	// 2024-01-01 is a Monday
+	date := func(day, hour, minute int) time.Time {
+		return time.Date(2024, time.January, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name       string
+		expression string
+		time       time.Time
+		want       bool
+	}{
+		{name: "every minute", expression: "* * * * *", time: date(1, 13, 37), want: true},
+		{name: "exact minute and hour", expression: "30 2 * * *", time: date(1, 2, 30), want: true},
+		{name: "other minute", expression: "30 2 * * *", time: date(1, 2, 31), want: false},
+		{name: "other hour", expression: "30 2 * * *", time: date(1, 3, 30), want: false},
+		{name: "step matches", expression: "*/15 * * * *", time: date(1, 5, 45), want: true},
+		{name: "step skips", expression: "*/15 * * * *", time: date(1, 5, 40), want: false},
+		{name: "step from a value", expression: "5/20 * * * *", time: date(1, 5, 45), want: true},
+		{name: "step from a value skips before it", expression: "5/20 * * * *", time: date(1, 5, 0), want: false},
+		{name: "step over a range", expression: "0 1-9/4 * * *", time: date(1, 9, 0), want: true},
+		{name: "step over a range skips", expression: "0 1-9/4 * * *", time: date(1, 7, 0), want: false},
+		{name: "range start", expression: "0 1-5 * * *", time: date(1, 1, 0), want: true},
+		{name: "range end", expression: "0 1-5 * * *", time: date(1, 5, 0), want: true},
+		{name: "after range", expression: "0 1-5 * * *", time: date(1, 6, 0), want: false},
+		{name: "list", expression: "0 0 * * 1,3", time: date(3, 0, 0), want: true},
+		{name: "not in list", expression: "0 0 * * 1,3", time: date(2, 0, 0), want: false},
+		{name: "month", expression: "0 0 * 1 *", time: date(1, 0, 0), want: true},
+		{name: "other month", expression: "0 0 * 2 *", time: date(1, 0, 0), want: false},
+		{name: "sunday as 0", expression: "0 0 * * 0", time: date(7, 0, 0), want: true},
+		{name: "sunday as 7", expression: "0 0 * * 7", time: date(7, 0, 0), want: true},
+		{name: "sunday as 7 in a range", expression: "0 0 * * 6-7", time: date(7, 0, 0), want: true},
+		{name: "saturday is not sunday", expression: "0 0 * * 7", time: date(6, 0, 0), want: false},
+		{name: "day of month only", expression: "0 0 15 * *", time: date(15, 0, 0), want: true},
+		{name: "other day of month", expression: "0 0 15 * *", time: date(14, 0, 0), want: false},
+		{name: "both days restricted, day of month matches", expression: "0 0 13 * 1", time: date(13, 0, 0), want: true},
+		{name: "both days restricted, day of week matches", expression: "0 0 13 * 1", time: date(8, 0, 0), want: true},
+		{name: "both days restricted, neither matches", expression: "0 0 13 * 1", time: date(9, 0, 0), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sched, err := parseSchedule(tt.expression)
+			if err != nil {
+				t.Fatalf("parseSchedule(%q) failed, %v", tt.expression, err)
+			}
+			if got := sched.matches(tt.time); got != tt.want {
+				t.Errorf("matches(%s) = %v, want %v", tt.time, got, tt.want)
+			}
+		})
+	}
+}
@@ -16,6 +16,7 @@ import (
 	addonv1alpha1client "open-cluster-management.io/api/client/addon/clientset/versioned"
 	clusterv1 "open-cluster-management.io/api/cluster/v1"
 	"strconv"
+	"time"
 )
 
 // agentValues is used for encapsulating template values for the Agent templates.
@@ -28,8 +29,12 @@ type agentValues struct {
 
 // deploymentValues is used for encapsulating template values extracted from the AddonDeploymentConfig.
 type deploymentValues struct {
-	AgentReplicas  int
-	AgentNamespace string
+	AgentReplicas         int
+	AgentNamespace        string
+	BackupInterval        string
+	VeleroNamespace       string
+	BackupStorageLocation string
+	BackupTTL             string
 }
 
 // createAgent is used for creating the Addon Agent configuration for the Addon Manager.
@@ -80,13 +85,29 @@ func getTemplateValuesFunc(options *Options) func(cluster *clusterv1.ManagedClus
 func loadDeploymentValuesFunc(config addonv1alpha1.AddOnDeploymentConfig) (addonfactory.Values, error) {
 	values := deploymentValues{}
 	for _, variable := range config.Spec.CustomizedVariables {
-		if variable.Name == "AgentReplicas" {
+		switch variable.Name {
+		case "AgentReplicas":
 			replicas, err := strconv.Atoi(variable.Value)
 			if err != nil {
 				return nil, err
 			}
 
 			values.AgentReplicas = replicas
+		// the backup variables are passed as the agent flags, durations are verified before deploying
+		case "BackupInterval":
+			if _, err := time.ParseDuration(variable.Value); err != nil {
+				return nil, err
+			}
+			values.BackupInterval = variable.Value
+		case "BackupTTL":
+			if _, err := time.ParseDuration(variable.Value); err != nil {
+				return nil, err
+			}
+			values.BackupTTL = variable.Value
+		case "VeleroNamespace":
+			values.VeleroNamespace = variable.Value
+		case "BackupStorageLocation":
+			values.BackupStorageLocation = variable.Value
 		}
 	}
 	// namespace from AddOnDeploymentConfig is set to its default open-cluster-management-agent-addon, we don't want it
@@ -33,6 +33,9 @@ type Options struct {
 	EnableValidation         bool
 	InstallAllStrategy       bool
 	InstallAllNamespace      string
+	EventSink                string
+	EventSinkType            string
+	EventSinkTopic           string
 }
 
 // NewManager is used as a factory for creating a Manager instance with an Options instance.
@@ -75,6 +78,9 @@ func (m *Manager) Run(ctx context.Context, kubeConfig *rest.Config) error {
 		ServiceAccount:   m.Options.ServiceAccount,
 		ConfigMapName:    m.Options.ConfigMapName,
 		EnableValidation: m.Options.EnableValidation,
+		EventSink:        m.Options.EventSink,
+		EventSinkType:    m.Options.EventSinkType,
+		EventSinkTopic:   m.Options.EventSinkTopic,
 	})
 
 	// blocking
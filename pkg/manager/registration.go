@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/mcra"
 	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/rand"
@@ -59,8 +60,8 @@ func getPermissionConfig(ctx context.Context, kubeConfig *rest.Config) agent.Per
 			return err
 		}
 
-		// create the role if not found
-		_, err = kubeClientSet.RbacV1().Roles(cluster.Name).Get(ctx, role.Name, metav1.GetOptions{})
+		// create the role if not found, update its rules if changed
+		existingRole, err := kubeClientSet.RbacV1().Roles(cluster.Name).Get(ctx, role.Name, metav1.GetOptions{})
 		switch {
 		case errors.IsNotFound(err):
 			_, createErr := kubeClientSet.RbacV1().Roles(cluster.Name).Create(ctx, &role, metav1.CreateOptions{})
@@ -69,6 +70,12 @@ func getPermissionConfig(ctx context.Context, kubeConfig *rest.Config) agent.Per
 			}
 		case err != nil:
 			return err
+		case !equality.Semantic.DeepEqual(existingRole.Rules, role.Rules):
+			existingRole.Rules = role.Rules
+			_, updateErr := kubeClientSet.RbacV1().Roles(cluster.Name).Update(ctx, existingRole, metav1.UpdateOptions{})
+			if updateErr != nil {
+				return updateErr
+			}
 		}
 
 		// load rolebinding from template, the rolebinding binds the aforementioned role to the addon group
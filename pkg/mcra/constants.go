@@ -10,4 +10,28 @@ const (
 	AnnotationCreatedBy              = "multicluster-resiliency-addon/created-by"
 	AnnotationPreviousSpoke          = "multicluster-resiliency-addon/previous-spoke"
 	AnnotationFromAnnotation         = "multicluster-resiliency-addon/copied-from"
+	AnnotationReplacementReason      = "multicluster-resiliency-addon/replacement-reason"
+	AnnotationRetainUntil            = "multicluster-resiliency-addon/retain-until"
+	AnnotationPreFailoverHooks       = "multicluster-resiliency-addon/pre-failover-hooks"
+	AnnotationFailoverDeferred       = "multicluster-resiliency-addon/failover-deferred"
+	AnnotationFailoverRefused        = "multicluster-resiliency-addon/failover-refused"
+	AnnotationMaintenanceStart       = "multicluster-resiliency-addon/maintenance-start"
+	AnnotationMaintenanceEnd         = "multicluster-resiliency-addon/maintenance-end"
+	AnnotationMaintenanceSchedule    = "multicluster-resiliency-addon/maintenance-schedule"
+	AnnotationMaintenanceDuration    = "multicluster-resiliency-addon/maintenance-duration"
+	AnnotationSuspend                = "multicluster-resiliency-addon/suspend"
+	AnnotationReevaluatedAt          = "multicluster-resiliency-addon/reevaluated-at"
+	LabelLogicalName                 = "multicluster-resiliency-addon/logical-name"
+	LabelRetained                    = "multicluster-resiliency-addon/retained"
+	LabelSpoke                       = "multicluster-resiliency-addon/spoke"
+	LabelRestoreFrom                 = "multicluster-resiliency-addon/restore-from"
+	LabelHook                        = "multicluster-resiliency-addon/hook"
+	LabelSmokeCheck                  = "multicluster-resiliency-addon/smoke-check"
+	InventoryConfigMapName           = "multicluster-resiliency-addon-inventory"
+	PreviousInventoryConfigMapName   = "multicluster-resiliency-addon-previous-inventory"
+	BackupConfigMapName              = "multicluster-resiliency-addon-backup"
+	OutboxConfigMapName              = "multicluster-resiliency-addon-outbox"
+	StatusConfigMapName              = "multicluster-resiliency-addon-status"
+	RestoreManifestWorkName          = "multicluster-resiliency-addon-restore"
+	SmokeCheckManifestWorkPrefix     = "multicluster-resiliency-addon-smoke-check-"
 )
@@ -16,6 +16,7 @@ const (
 	LabelPoolName     = "pool_name"
 	LabelOldSpokeName = "old_spoke_name"
 	LabelNewSpokeName = "new_spoke_name"
+	LabelReason       = "reason"
 )
 
 var ResilientSpokeNotAvailable = *prometheus.NewCounterVec(prometheus.CounterOpts{
@@ -38,6 +39,21 @@ var NewSpokeReady = *prometheus.NewCounterVec(prometheus.CounterOpts{
 	Help: "Count the time we got a new ready cluster",
 }, []string{LabelOldSpokeName, LabelNewSpokeName})
 
+var FailoverRefused = *prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "failover_refused",
+	Help: "Count the times we refused replacing a not available cluster",
+}, []string{LabelSpokeName, LabelReason})
+
+var FailoverCircuitBreakerOpen = prometheus.NewGauge(prometheus.GaugeOpts{
+	Name: "failover_circuit_breaker_open",
+	Help: "Set to 1 while the failovers are halted for too many not available clusters",
+})
+
+var FailoversInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+	Name: "failovers_in_flight",
+	Help: "The number of clusters being replaced",
+})
+
 // init is registering the metrics with K8S registry.
 func init() {
 	metrics.Registry.MustRegister(
@@ -45,5 +61,8 @@ func init() {
 		ResilientSpokeAvailable,
 		NewClusterClaimCreated,
 		NewSpokeReady,
+		FailoverRefused,
+		FailoverCircuitBreakerOpen,
+		FailoversInFlight,
 	)
 }
@@ -0,0 +1,43 @@
+// Copyright (c) 2023 Red Hat, Inc.
+
+package notify
+
+// This file contains functions for formatting notifications.
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/cloudevents"
+)
+
+// format is used for serializing the Event per the Format. Returns the content type and the body.
+func format(f Format, event Event) (string, []byte, error) {
+	switch f {
+	case FormatSlack:
+		body, err := json.Marshal(map[string]string{"text": slackText(event)})
+		return "application/json", body, err
+	case FormatCloudEvents:
+		// structured content mode, the event is the data of the CloudEvent
+		cloudEvent, err := cloudevents.New(string(event.Type), event.Spoke, event.Time.Time, event)
+		if err != nil {
+			return "", nil, err
+		}
+		body, err := json.Marshal(cloudEvent)
+		return "application/cloudevents+json", body, err
+	default:
+		body, err := json.Marshal(event)
+		return "application/json", body, err
+	}
+}
+
+// slackText is used for describing the Event as a Slack message text.
+func slackText(event Event) string {
+	text := fmt.Sprintf("*%s* - cluster `%s`", event.Type, event.Spoke)
+	if event.LogicalName != "" && event.LogicalName != event.Spoke {
+		text += fmt.Sprintf(" (logical name `%s`)", event.LogicalName)
+	}
+	if event.NewSpoke != "" {
+		text += fmt.Sprintf(" replaced by `%s`", event.NewSpoke)
+	}
+	return fmt.Sprintf("%s: %s", text, event.Message)
+}
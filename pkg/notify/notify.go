@@ -0,0 +1,132 @@
+// Copyright (c) 2023 Red Hat, Inc.
+
+package notify
+
+// This file contains types and functions for sending notifications about replacing Spoke clusters.
+
+import (
+	"context"
+	"fmt"
+	"github.com/rhecosystemappeng/multicluster-resiliency-addon/pkg/endpoint"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"time"
+)
+
+// sendTimeout is the time a notifier is allowed to respond, notifications are not retried.
+const sendTimeout = 10 * time.Second
+
+type (
+	// EventType is the type of transition a notification is sent for, use EventSpokeUnavailable, EventClaimCreated,
+	// EventClaimReady, EventActionFailed, and EventReplacementComplete.
+	EventType string
+
+	// Format is the format of the notifications sent to a notifier, use FormatWebhook, FormatSlack, and
+	// FormatCloudEvents.
+	Format string
+
+	// Notifier describes an HTTP endpoint notifications are POSTed to.
+	Notifier struct {
+		Name string `json:"name"`
+		// Format optionally overrides FormatWebhook.
+		Format            Format `json:"format,omitempty"`
+		endpoint.Endpoint `json:",inline"`
+		// Events optionally restricts the notifier to the listed event types.
+		Events []EventType `json:"events,omitempty"`
+	}
+
+	// Event describes a transition in replacing a Spoke cluster. The Spoke is the cluster being replaced, the NewSpoke
+	// is only known once the claim is ready.
+	Event struct {
+		Type        EventType   `json:"type"`
+		Time        metav1.Time `json:"time"`
+		Spoke       string      `json:"spoke"`
+		NewSpoke    string      `json:"newSpoke,omitempty"`
+		LogicalName string      `json:"logicalName,omitempty"`
+		Message     string      `json:"message"`
+	}
+)
+
+const (
+	EventSpokeUnavailable    EventType = "SpokeUnavailable"
+	EventClaimCreated        EventType = "ClaimCreated"
+	EventClaimReady          EventType = "ClaimReady"
+	EventActionFailed        EventType = "ActionFailed"
+	EventReplacementComplete EventType = "ReplacementComplete"
+
+	FormatWebhook     Format = "webhook"
+	FormatSlack       Format = "slack"
+	FormatCloudEvents Format = "cloudevents"
+)
+
+// Validate is used for verifying a Notifier is well-defined.
+func (n *Notifier) Validate() error {
+	if n.Name == "" {
+		return fmt.Errorf("notifier name is required")
+	}
+	if n.URL == "" {
+		return fmt.Errorf("notifier %s url is required", n.Name)
+	}
+	switch n.Format {
+	case "", FormatWebhook, FormatSlack, FormatCloudEvents:
+	default:
+		return fmt.Errorf("notifier %s has unknown format %s", n.Name, n.Format)
+	}
+	for _, eventType := range n.Events {
+		switch eventType {
+		case EventSpokeUnavailable, EventClaimCreated, EventClaimReady, EventActionFailed, EventReplacementComplete:
+		default:
+			return fmt.Errorf("notifier %s has unknown event %s", n.Name, eventType)
+		}
+	}
+	return nil
+}
+
+// wants returns true if the Notifier is not restricted to event types other than the given one.
+func (n *Notifier) wants(eventType EventType) bool {
+	if len(n.Events) == 0 {
+		return true
+	}
+	for _, wanted := range n.Events {
+		if wanted == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Send is used for sending the Event to all the notifiers wanting it. Notifications are best-effort, failures are
+// logged and not retried. The headers secrets are read from the namespace through the reader.
+func Send(ctx context.Context, reader client.Reader, namespace string, notifiers []Notifier, event Event) {
+	logger := log.FromContext(ctx)
+
+	if event.Time.IsZero() {
+		event.Time = metav1.Now()
+	}
+
+	for _, notifier := range notifiers {
+		if !notifier.wants(event.Type) {
+			continue
+		}
+
+		if err := send(ctx, reader, namespace, notifier, event); err != nil {
+			logger.Error(err, "failed sending notification", "notifier", notifier.Name, "event", event.Type)
+			continue
+		}
+		logger.Info("notification sent", "notifier", notifier.Name, "event", event.Type)
+	}
+}
+
+// send is used for POSTing the Event to the Notifier's URL, formatted per the Notifier's format.
+func send(ctx context.Context, reader client.Reader, namespace string, notifier Notifier, event Event) error {
+	contentType, body, err := format(notifier.Format, event)
+	if err != nil {
+		return err
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+
+	return notifier.Post(sendCtx, reader, namespace, contentType, body)
+}